@@ -1,15 +1,7 @@
 package base
 
-import (
-	"fmt"
-	"image"
-
-	u "github.com/gassyrdaulet/go-fighting-game/utils"
-	"github.com/hajimehoshi/ebiten/v2"
-)
-
 type Animation struct {
-	Frames     		[]*ebiten.Image
+	FrameCount 		int
 	FrameIndex 		int
 	FrameTick  		int
 	FrameSpeed 		int
@@ -53,35 +45,12 @@ func Anim(
 	}
 }
 
-func loadFrames(cfg *AnimationConfig) ([]*ebiten.Image, error) {
-	img, err := u.LoadImage(cfg.FilePath)
-	if err != nil {
-		return nil, fmt.Errorf("cannot load file: %s %s", cfg.Name, cfg.FilePath)
-	}
-
-	frames := make([]*ebiten.Image, cfg.Count)
-	for i := 0; i < cfg.Count; i++ {
-		sx0 := i*cfg.Width + cfg.StartX
-		sy0 := cfg.StartY
-		sx1 := sx0 + cfg.Width
-		sy1 := sy0 + cfg.Height
-
-		sub := img.SubImage(image.Rect(sx0, sy0, sx1, sy1)).(*ebiten.Image)
-		frames[i] = sub
-	}
-	return frames, nil
-}
-
-func LoadAnimations(cfgs []AnimationConfig) (map[string]*Animation, error) {
+func NewAnimations(cfgs []AnimationConfig) map[string]*Animation {
 	animations := map[string]*Animation{}
 
 	for _, cfg := range cfgs {
-		frames, err := loadFrames(&cfg)
-		if err != nil {
-			return nil, err
-		}
 		animations[cfg.Name] = &Animation{
-			Frames:     frames,
+			FrameCount: cfg.Count,
 			FrameSpeed: cfg.FrameSpeed,
 			Loop:       cfg.Loop,
 			XO:         cfg.XO,
@@ -89,7 +58,7 @@ func LoadAnimations(cfgs []AnimationConfig) (map[string]*Animation, error) {
 		}
 	}
 
-	return animations, nil
+	return animations
 }
 
 type Animator struct {
@@ -111,7 +80,7 @@ func (a *Animator) UpdateFrame(animation string) {
 		return
 	}
 	anim, ok := a.Animations[animation]
-	if !ok || anim == nil || len(anim.Frames) == 0 {
+	if !ok || anim == nil || anim.FrameCount == 0 {
 		return
 	}
 
@@ -125,28 +94,36 @@ func (a *Animator) UpdateFrame(animation string) {
 	if anim.FrameTick >= anim.FrameSpeed {
 		anim.FrameTick = 0
 		if anim.Loop {
-			anim.FrameIndex = (anim.FrameIndex + 1) % len(anim.Frames)
-		} else if anim.FrameIndex < len(anim.Frames)-1 {
+			anim.FrameIndex = (anim.FrameIndex + 1) % anim.FrameCount
+		} else if anim.FrameIndex < anim.FrameCount-1 {
 			anim.FrameIndex++
 		}
 	}
 }
 
-func (a *Animator) DrawFrame(screen *ebiten.Image, x, y float64, flip bool) {
-	anim, ok := a.Animations[a.CurrentAnimation]
-	if !ok || anim == nil || len(anim.Frames) == 0 {
-		return
+// AnimatorState is a copy of the playback position of every animation, so
+// an Animator can be rolled back without touching its frames.
+type AnimatorState struct {
+	CurrentAnimation string
+	Animations       map[string]Animation
+}
+
+func (a *Animator) Save() AnimatorState {
+	state := AnimatorState{
+		CurrentAnimation: a.CurrentAnimation,
+		Animations:       make(map[string]Animation, len(a.Animations)),
 	}
-	currentFrame := anim.Frames[anim.FrameIndex]
-	frameWidth := float64(currentFrame.Bounds().Dx())
+	for name, anim := range a.Animations {
+		state.Animations[name] = *anim
+	}
+	return state
+}
 
-	op := &ebiten.DrawImageOptions{}
-	if flip {
-		op.GeoM.Scale(-a.SpriteScaleX, a.SpriteScaleY)
-		op.GeoM.Translate(x+(frameWidth/2)*a.SpriteScaleX-anim.XO, y-anim.YO)
-	} else {
-		op.GeoM.Scale(a.SpriteScaleX, a.SpriteScaleY)
-		op.GeoM.Translate(x-(frameWidth/2)*a.SpriteScaleX+anim.XO, y-anim.YO)
+func (a *Animator) Restore(state AnimatorState) {
+	a.CurrentAnimation = state.CurrentAnimation
+	for name, anim := range state.Animations {
+		if current, ok := a.Animations[name]; ok {
+			*current = anim
+		}
 	}
-	screen.DrawImage(currentFrame, op)
 }
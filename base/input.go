@@ -6,4 +6,5 @@ type Input struct {
 	Up     bool
 	Down   bool
 	Attack bool
+	Block  bool
 }
\ No newline at end of file
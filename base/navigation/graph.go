@@ -0,0 +1,337 @@
+package navigation
+
+import (
+	"math"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/characters"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+)
+
+type EdgeKind int
+
+const (
+	Walk EdgeKind = iota
+	Fall
+	Jump
+)
+
+const (
+	maxSimTicks     = 240
+	jumpReachRows   = 8
+	jumpCostPenalty = constants.TileSize * 2
+	fallCostPenalty = constants.TileSize / 2
+)
+
+type Edge struct {
+	To   int
+	Kind EdgeKind
+	Cost float64
+}
+
+// Node is a single tile an actor can stand on. X is the tile's center and Y is
+// its top, so it matches an actor's feet when it is standing there.
+type Node struct {
+	ID           int
+	TileX, TileY int
+	X, Y         float64
+	Edges        []Edge
+}
+
+// Graph is the walkable surfaces of a tilemap as seen by one character: jump
+// and fall edges depend on how high and how far that character can get.
+type Graph struct {
+	Nodes  []*Node
+	tiles  *base.TileMap
+	byTile map[[2]int]int
+
+	width, height float64
+	airSpeed      float64
+	jumpForce     float64
+	gravity       float64
+}
+
+func Build(tm *base.TileMap, char *characters.Character) *Graph {
+	g := &Graph{
+		tiles:     tm,
+		byTile:    make(map[[2]int]int),
+		width:     char.Width,
+		height:    char.Height,
+		airSpeed:  char.Speed * 5 / 6,
+		jumpForce: char.JumpForce,
+		gravity:   constants.Gravity * char.Weight,
+	}
+
+	for ty := 0; ty < tm.Height; ty++ {
+		for tx := 0; tx < tm.Width; tx++ {
+			if g.standable(tx, ty) {
+				g.addNode(tx, ty)
+			}
+		}
+	}
+
+	for _, n := range g.Nodes {
+		g.linkWalk(n)
+		g.linkFall(n)
+		g.linkJump(n)
+	}
+
+	return g
+}
+
+func BuildAll(tm *base.TileMap, chars map[string]*characters.Character) map[string]*Graph {
+	graphs := make(map[string]*Graph, len(chars))
+	for id, char := range chars {
+		graphs[id] = Build(tm, char)
+	}
+	return graphs
+}
+
+// NodeAt returns the node under the given feet position. When the position is
+// in the air it looks straight down for the surface the actor would land on.
+func (g *Graph) NodeAt(x, y float64) (*Node, bool) {
+	tx := int(x / constants.TileSize)
+	ty := int(y / constants.TileSize)
+
+	for ; ty < g.tiles.Height; ty++ {
+		if id, ok := g.byTile[[2]int{tx, ty}]; ok {
+			return g.Nodes[id], true
+		}
+		if g.tiles.IsSolid(tx, ty) || g.tiles.IsSlope(tx, ty) {
+			break
+		}
+	}
+
+	return nil, false
+}
+
+// Nearest returns the node closest to the given feet position.
+func (g *Graph) Nearest(x, y float64) (*Node, bool) {
+	if n, ok := g.NodeAt(x, y); ok {
+		return n, true
+	}
+
+	var nearest *Node
+	best := math.MaxFloat64
+	for _, n := range g.Nodes {
+		d := math.Hypot(n.X-x, n.Y-y)
+		if d < best {
+			best = d
+			nearest = n
+		}
+	}
+
+	return nearest, nearest != nil
+}
+
+func (g *Graph) standable(tx, ty int) bool {
+	if !g.tiles.IsSolid(tx, ty) && !g.tiles.IsPlatform(tx, ty) && !g.tiles.IsSlope(tx, ty) {
+		return false
+	}
+
+	rows := int(math.Ceil(g.height / constants.TileSize))
+	for r := 1; r <= rows; r++ {
+		if ty-r < 0 || g.tiles.IsSolid(tx, ty-r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (g *Graph) addNode(tx, ty int) {
+	n := &Node{
+		ID:    len(g.Nodes),
+		TileX: tx,
+		TileY: ty,
+		X:     (float64(tx) + 0.5) * constants.TileSize,
+		Y:     float64(ty) * constants.TileSize,
+	}
+	g.byTile[[2]int{tx, ty}] = n.ID
+	g.Nodes = append(g.Nodes, n)
+}
+
+func (g *Graph) node(tx, ty int) (*Node, bool) {
+	id, ok := g.byTile[[2]int{tx, ty}]
+	if !ok {
+		return nil, false
+	}
+	return g.Nodes[id], true
+}
+
+func (g *Graph) addEdge(from, to *Node, kind EdgeKind, penalty float64) {
+	if from == to {
+		return
+	}
+	for _, e := range from.Edges {
+		if e.To == to.ID && e.Kind <= kind {
+			return
+		}
+	}
+	from.Edges = append(from.Edges, Edge{
+		To:   to.ID,
+		Kind: kind,
+		Cost: math.Hypot(to.X-from.X, to.Y-from.Y) + penalty,
+	})
+}
+
+func (g *Graph) linkWalk(n *Node) {
+	for _, dir := range []int{-1, 1} {
+		if next, ok := g.node(n.TileX+dir, n.TileY); ok {
+			g.addEdge(n, next, Walk, 0)
+		}
+	}
+}
+
+// linkFall walks off both ends of a surface and follows the body down until it
+// lands, once while still holding the direction and once dropping straight.
+func (g *Graph) linkFall(n *Node) {
+	for _, dir := range []int{-1, 1} {
+		if _, ok := g.node(n.TileX+dir, n.TileY); ok {
+			continue
+		}
+		if g.tiles.IsSolid(n.TileX+dir, n.TileY) || g.tiles.IsSolid(n.TileX+dir, n.TileY-1) {
+			continue
+		}
+
+		edgeX := float64(n.TileX+1) * constants.TileSize
+		if dir < 0 {
+			edgeX = float64(n.TileX) * constants.TileSize
+		}
+		offX := edgeX + float64(dir)*(g.width/2+1)
+
+		for _, hold := range []bool{true, false} {
+			if to, ok := g.simulateFall(n, dir, offX, hold); ok {
+				g.addEdge(n, to, Fall, fallCostPenalty)
+			}
+		}
+	}
+}
+
+// simulateFall starts at the moment the body is no longer over the surface.
+func (g *Graph) simulateFall(n *Node, dir int, offX float64, hold bool) (*Node, bool) {
+	x, feet := offX, n.Y
+	vy := 0.0
+
+	for tick := 0; tick < maxSimTicks; tick++ {
+		if hold {
+			nx := x + float64(dir)*g.airSpeed
+			if !g.hitsSolid(nx, feet) {
+				x = nx
+			}
+		}
+
+		prevFeet := feet
+		vy += g.gravity
+		feet += vy
+
+		if to, ok := g.landing(x, prevFeet, feet); ok {
+			if to == n {
+				return nil, false
+			}
+			return to, true
+		}
+		if feet > float64(g.tiles.Height)*constants.TileSize {
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+func (g *Graph) linkJump(n *Node) {
+	maxRise := g.jumpForce * g.jumpForce / (2 * g.gravity)
+	rise := int(math.Ceil(maxRise/constants.TileSize)) + 1
+	airTicks := 2 * math.Abs(g.jumpForce) / g.gravity
+	reach := int(math.Ceil(airTicks*g.airSpeed/constants.TileSize)) + 1
+
+	for ty := n.TileY - rise; ty <= n.TileY+jumpReachRows; ty++ {
+		for tx := n.TileX - reach; tx <= n.TileX+reach; tx++ {
+			to, ok := g.node(tx, ty)
+			if !ok || to == n {
+				continue
+			}
+			if ty == n.TileY && abs(tx-n.TileX) <= 1 {
+				continue
+			}
+			if g.simulateJump(n, to) {
+				g.addEdge(n, to, Jump, jumpCostPenalty)
+			}
+		}
+	}
+}
+
+// simulateJump integrates the jump the same way physics.World.Step does and
+// steers towards the target at air speed, failing on any solid tile or on a
+// landing anywhere else.
+func (g *Graph) simulateJump(from, to *Node) bool {
+	x, feet := from.X, from.Y
+	vy := g.jumpForce
+
+	for tick := 0; tick < maxSimTicks; tick++ {
+		dx := to.X - x
+		step := math.Min(math.Abs(dx), g.airSpeed)
+		x += math.Copysign(step, dx)
+
+		prevFeet := feet
+		vy += g.gravity
+		feet += vy
+
+		if feet-g.height < 0 {
+			return false
+		}
+		// The feet end up inside the tile they land on, so a landing
+		// only has to be clear of solid tiles on top of it.
+		if vy > 0 {
+			if land, ok := g.landing(x, prevFeet, feet); ok {
+				return land == to && !g.hitsSolid(x, land.Y)
+			}
+		}
+		if g.hitsSolid(x, feet) {
+			return false
+		}
+		if feet > to.Y+constants.TileSize && vy > 0 {
+			return false
+		}
+	}
+
+	return false
+}
+
+// landing returns the node whose top was crossed by the feet during a tick.
+func (g *Graph) landing(x, prevFeet, feet float64) (*Node, bool) {
+	tx := int(x / constants.TileSize)
+	for ty := int(prevFeet / constants.TileSize); ty <= int(feet/constants.TileSize); ty++ {
+		top := float64(ty) * constants.TileSize
+		if prevFeet > top || feet < top {
+			continue
+		}
+		if n, ok := g.node(tx, ty); ok {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+func (g *Graph) hitsSolid(x, feet float64) bool {
+	tileX1 := int((x - g.width/2) / constants.TileSize)
+	tileX2 := int((x + g.width/2) / constants.TileSize)
+	tileY1 := int((feet - g.height) / constants.TileSize)
+	tileY2 := int((feet - 1) / constants.TileSize)
+
+	for ty := tileY1; ty <= tileY2; ty++ {
+		for tx := tileX1; tx <= tileX2; tx++ {
+			if g.tiles.IsSolid(tx, ty) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,130 @@
+package navigation
+
+import (
+	"testing"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/characters"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+)
+
+// testMap is a floor with a ledge two rows above it on the left, within a
+// jump, and a block on the right too high up to jump onto.
+var testMap = []string{
+	"................",
+	"............##..",
+	"................",
+	"................",
+	"................",
+	"................",
+	"................",
+	"..###...........",
+	"................",
+	"################",
+}
+
+var testChar = &characters.Character{Speed: 3.8, JumpForce: -9.5, Weight: 0.9, Width: 12, Height: 26}
+
+func newTestGraph(t *testing.T) *Graph {
+	t.Helper()
+
+	const solid = 2
+	tm := base.NewTileMap(len(testMap[0]), len(testMap), constants.TileSize)
+	tm.AddTileType(solid, base.Solid)
+	for y, row := range testMap {
+		for x, c := range row {
+			if c == '#' {
+				tm.SetTile(x, y, solid)
+			}
+		}
+	}
+	return Build(tm, testChar)
+}
+
+func mustNode(t *testing.T, g *Graph, tx, ty int) *Node {
+	t.Helper()
+
+	n, ok := g.node(tx, ty)
+	if !ok {
+		t.Fatalf("no node on tile %d, %d", tx, ty)
+	}
+	return n
+}
+
+// edgeTo tells whether from has an edge of kind to a node on row ty, and to
+// which one.
+func edgeTo(g *Graph, from *Node, kind EdgeKind, ty int) (*Node, bool) {
+	for _, e := range from.Edges {
+		if to := g.Nodes[e.To]; e.Kind == kind && to.TileY == ty {
+			return to, true
+		}
+	}
+	return nil, false
+}
+
+func TestBuildLinks(t *testing.T) {
+	g := newTestGraph(t)
+
+	if n := len(g.Nodes); n != 16+3+2 {
+		t.Errorf("%d nodes, want one on top of each of the 21 solid tiles", n)
+	}
+
+	left, right := mustNode(t, g, 2, 7), mustNode(t, g, 3, 7)
+	if to, ok := edgeTo(g, left, Walk, 7); !ok || to != right {
+		t.Errorf("no walk along the ledge from %d to %d", left.TileX, right.TileX)
+	}
+
+	edge := mustNode(t, g, 4, 7)
+	if to, ok := edgeTo(g, edge, Fall, 9); !ok || to.TileX < 5 {
+		t.Errorf("no fall off the right of the ledge onto the floor")
+	}
+
+	floor := mustNode(t, g, 6, 9)
+	if _, ok := edgeTo(g, floor, Jump, 7); !ok {
+		t.Errorf("no jump from the floor onto the ledge")
+	}
+	if _, ok := edgeTo(g, floor, Jump, 1); ok {
+		t.Errorf("a jump onto the block out of reach")
+	}
+}
+
+func TestPathBetweenPlatforms(t *testing.T) {
+	g := newTestGraph(t)
+	start, goal := mustNode(t, g, 10, 9), mustNode(t, g, 2, 7)
+
+	route, ok := g.Path(start.ID, goal.ID)
+	if !ok {
+		t.Fatal("no route from the floor onto the ledge")
+	}
+	if route[0].NodeID != start.ID || route[len(route)-1].NodeID != goal.ID {
+		t.Fatalf("route from %d to %d, want from %d to %d", route[0].NodeID, route[len(route)-1].NodeID, start.ID, goal.ID)
+	}
+
+	jumped := false
+	for i, wp := range route[1:] {
+		from := g.Nodes[route[i].NodeID]
+		linked := false
+		for _, e := range from.Edges {
+			linked = linked || e.To == wp.NodeID && e.Kind == wp.Kind
+		}
+		if !linked {
+			t.Errorf("waypoint %d: no %v edge from node %d to %d", i+1, wp.Kind, from.ID, wp.NodeID)
+		}
+		jumped = jumped || wp.Kind == Jump
+	}
+	if !jumped {
+		t.Error("got onto the ledge without a jump")
+	}
+}
+
+func TestPathNoRoute(t *testing.T) {
+	g := newTestGraph(t)
+	start, goal := mustNode(t, g, 10, 9), mustNode(t, g, 12, 1)
+
+	if route, ok := g.Path(start.ID, goal.ID); ok || route != nil {
+		t.Errorf("Path = %v, %v onto the block out of reach, want no route", route, ok)
+	}
+	if _, ok := g.Path(goal.ID, start.ID); !ok {
+		t.Error("no route down from the block")
+	}
+}
@@ -0,0 +1,107 @@
+package navigation
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Waypoint is one stop of a route. Kind tells how the actor gets there from the
+// previous waypoint; the first waypoint is where the route starts.
+type Waypoint struct {
+	NodeID int
+	X, Y   float64
+	Kind   EdgeKind
+}
+
+// FindPath runs A* between two feet positions and returns the route including
+// the start node. ok is false when the target can't be reached.
+func (g *Graph) FindPath(fromX, fromY, toX, toY float64) ([]Waypoint, bool) {
+	start, ok := g.Nearest(fromX, fromY)
+	if !ok {
+		return nil, false
+	}
+	goal, ok := g.Nearest(toX, toY)
+	if !ok {
+		return nil, false
+	}
+
+	return g.Path(start.ID, goal.ID)
+}
+
+func (g *Graph) Path(startID, goalID int) ([]Waypoint, bool) {
+	goal := g.Nodes[goalID]
+
+	cost := map[int]float64{startID: 0}
+	cameFrom := map[int]Edge{}
+	open := &queue{}
+	heap.Push(open, &item{id: startID, priority: g.heuristic(g.Nodes[startID], goal)})
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(*item)
+		if current.id == goalID {
+			return g.reconstruct(cameFrom, startID, goalID), true
+		}
+		if current.priority > cost[current.id]+g.heuristic(g.Nodes[current.id], goal) {
+			continue
+		}
+
+		for _, e := range g.Nodes[current.id].Edges {
+			next := cost[current.id] + e.Cost
+			if old, seen := cost[e.To]; seen && next >= old {
+				continue
+			}
+			cost[e.To] = next
+			cameFrom[e.To] = Edge{To: current.id, Kind: e.Kind, Cost: e.Cost}
+			heap.Push(open, &item{id: e.To, priority: next + g.heuristic(g.Nodes[e.To], goal)})
+		}
+	}
+
+	return nil, false
+}
+
+func (g *Graph) heuristic(a, b *Node) float64 {
+	return math.Hypot(b.X-a.X, b.Y-a.Y)
+}
+
+func (g *Graph) reconstruct(cameFrom map[int]Edge, startID, goalID int) []Waypoint {
+	var route []Waypoint
+
+	id := goalID
+	for id != startID {
+		prev := cameFrom[id]
+		n := g.Nodes[id]
+		route = append(route, Waypoint{NodeID: id, X: n.X, Y: n.Y, Kind: prev.Kind})
+		id = prev.To
+	}
+	start := g.Nodes[startID]
+	route = append(route, Waypoint{NodeID: startID, X: start.X, Y: start.Y, Kind: Walk})
+
+	for i, j := 0, len(route)-1; i < j; i, j = i+1, j-1 {
+		route[i], route[j] = route[j], route[i]
+	}
+
+	return route
+}
+
+type item struct {
+	id       int
+	priority float64
+}
+
+type queue []*item
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+
+func (q queue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *queue) Push(x any) { *q = append(*q, x.(*item)) }
+
+func (q *queue) Pop() any {
+	old := *q
+	n := len(old)
+	it := old[n-1]
+	*q = old[:n-1]
+	return it
+}
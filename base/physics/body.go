@@ -6,6 +6,9 @@ type Body struct {
 	Width, Height float64
 	OnGround      bool
 	Weight        float64
+	// DropThrough makes the next Step fall through platform tiles instead of
+	// landing on them.
+	DropThrough bool
 }
 
 func (b *Body) Position() (x, y float64) {
@@ -66,3 +69,26 @@ func (b *Body) GetWeight() float64 {
 func (b *Body) SetWeight(weight float64) {
 	b.Weight = weight
 }
+
+func (b *Body) DropsThrough() bool {
+	return b.DropThrough
+}
+
+func (b *Body) SetDropThrough(drop bool) {
+	b.DropThrough = drop
+}
+
+// PhysicsBody is the body itself, so bodies can be told apart behind a
+// PhysicalBody.
+func (b *Body) PhysicsBody() *Body {
+	return b
+}
+
+// Save returns a copy of the body for Restore to bring back later.
+func (b *Body) Save() Body {
+	return *b
+}
+
+func (b *Body) Restore(state Body) {
+	*b = state
+}
@@ -15,5 +15,8 @@ type PhysicalBody interface {
 	SetOnGround(onGround bool)
 	GetWeight() float64
 	SetWeight(weight float64)
+	DropsThrough() bool
+	SetDropThrough(drop bool)
+	PhysicsBody() *Body
 	Die()
 }
\ No newline at end of file
@@ -0,0 +1,121 @@
+package physics
+
+import "math"
+
+// PathMode is how a platform goes round its waypoints.
+type PathMode int
+
+const (
+	// PingPong goes to the last waypoint and back the same way.
+	PingPong PathMode = iota
+	// Loop goes from the last waypoint straight on to the first.
+	Loop
+)
+
+type Point struct {
+	X, Y float64
+}
+
+// Platform is a box moving along a path of waypoints. Like a Body, X is its
+// middle and Y its top. Bodies land on top of it and ride along. A solid
+// platform also stops them from the sides and from below; any other one lets
+// them jump up through it and drop down through it, like a platform tile.
+type Platform struct {
+	X, Y          float64
+	Width, Height float64
+	Solid         bool
+	Path          []Point
+	Speed         float64
+	Mode          PathMode
+	// Wait is how many ticks the platform stops at every waypoint.
+	Wait int
+
+	// DX and DY are how far the platform moved in the last tick.
+	DX, DY    float64
+	Target    int
+	Reverse   bool
+	WaitTicks int
+}
+
+// NewPlatform puts a platform on the first waypoint of path, heading for the
+// second.
+func NewPlatform(width, height float64, path []Point, speed float64, mode PathMode) *Platform {
+	p := &Platform{
+		Width:  width,
+		Height: height,
+		Path:   path,
+		Speed:  speed,
+		Mode:   mode,
+	}
+	if len(path) > 0 {
+		p.X, p.Y = path[0].X, path[0].Y
+	}
+	if len(path) > 1 {
+		p.Target = 1
+	}
+	return p
+}
+
+// Move takes the platform one tick further along its path.
+func (p *Platform) Move() {
+	p.DX, p.DY = 0, 0
+	if len(p.Path) < 2 {
+		return
+	}
+	if p.WaitTicks > 0 {
+		p.WaitTicks--
+		return
+	}
+
+	target := p.Path[p.Target]
+	dx, dy := target.X-p.X, target.Y-p.Y
+	dist := math.Sqrt(dx*dx + dy*dy)
+	if dist <= p.Speed {
+		p.DX, p.DY = dx, dy
+		p.X, p.Y = target.X, target.Y
+		p.next()
+		p.WaitTicks = p.Wait
+		return
+	}
+
+	p.DX, p.DY = dx/dist*p.Speed, dy/dist*p.Speed
+	p.X += p.DX
+	p.Y += p.DY
+}
+
+func (p *Platform) next() {
+	last := len(p.Path) - 1
+	switch {
+	case p.Mode == Loop:
+		p.Target = (p.Target + 1) % len(p.Path)
+	case p.Reverse && p.Target == 0:
+		p.Reverse = false
+		p.Target = 1
+	case !p.Reverse && p.Target == last:
+		p.Reverse = true
+		p.Target = last - 1
+	case p.Reverse:
+		p.Target--
+	default:
+		p.Target++
+	}
+}
+
+// overlapsX tells whether a body wid wide, with its middle at x, is over or
+// under the platform.
+func (p *Platform) overlapsX(x, wid float64) bool {
+	return x+wid/2 > p.X-p.Width/2 && x-wid/2 < p.X+p.Width/2
+}
+
+func (p *Platform) overlaps(x, y, wid, h float64) bool {
+	return p.overlapsX(x, wid) && y+h > p.Y && y < p.Y+p.Height
+}
+
+// Save returns a copy of the platform for Restore to bring back later.
+func (p *Platform) Save() Platform {
+	return *p
+}
+
+func (p *Platform) Restore(state Platform) {
+	*p = state
+}
@@ -0,0 +1,81 @@
+package physics
+
+import (
+	"slices"
+	"testing"
+)
+
+// square is a path round a square with sides one tick of speed 10 long.
+var square = []Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}}
+
+// positions moves p ticks times and returns where it is after every tick.
+func positions(p *Platform, ticks int) []Point {
+	var got []Point
+	for range ticks {
+		p.Move()
+		got = append(got, Point{X: p.X, Y: p.Y})
+	}
+	return got
+}
+
+func TestPlatformPath(t *testing.T) {
+	tests := []struct {
+		name string
+		mode PathMode
+		wait int
+		want []Point
+	}{
+		{
+			name: "ping-pong",
+			mode: PingPong,
+			want: []Point{{10, 0}, {10, 10}, {0, 10}, {10, 10}, {10, 0}, {0, 0}, {10, 0}},
+		},
+		{
+			name: "loop",
+			mode: Loop,
+			want: []Point{{10, 0}, {10, 10}, {0, 10}, {0, 0}, {10, 0}, {10, 10}, {0, 10}},
+		},
+		{
+			name: "wait",
+			mode: Loop,
+			wait: 1,
+			want: []Point{{10, 0}, {10, 0}, {10, 10}, {10, 10}, {0, 10}, {0, 10}, {0, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlatform(32, 8, square, 10, tt.mode)
+			p.Wait = tt.wait
+			if got := positions(p, len(tt.want)); !slices.Equal(got, tt.want) {
+				t.Errorf("positions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlatformMovesPartWay(t *testing.T) {
+	p := NewPlatform(32, 8, square, 4, PingPong)
+
+	want := []Point{{4, 0}, {8, 0}, {10, 0}, {10, 4}}
+	if got := positions(p, len(want)); !slices.Equal(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+	if p.DX != 0 || p.DY != 4 {
+		t.Errorf("moved %v, %v in the last tick, want 0, 4", p.DX, p.DY)
+	}
+}
+
+func TestPlatformRestore(t *testing.T) {
+	p := NewPlatform(32, 8, square, 3, PingPong)
+	p.Wait = 2
+	positions(p, 20)
+
+	saved := p.Save()
+	want := positions(p, 40)
+
+	p.Restore(saved)
+	if got := positions(p, 40); !slices.Equal(got, want) {
+		t.Errorf("after Restore positions = %v, want %v", got, want)
+	}
+}
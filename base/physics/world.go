@@ -2,6 +2,7 @@ package physics
 
 import (
 	"math"
+	"slices"
 
 	"github.com/gassyrdaulet/go-fighting-game/base"
 	"github.com/gassyrdaulet/go-fighting-game/constants"
@@ -12,19 +13,65 @@ type World struct {
 	VirtualBorderLeftX  float64
 	VirtualBorderRightX float64
 	Width, Height       float64
+	Platforms           []*Platform
+	// Bodies collide with each other. Any other body passes through them.
+	Bodies              []*Body
 	paused 				bool
 }
 
-func (w *World) Step(p PhysicalBody) {
+// Contact is something a body touched: a hazard tile at TileX, TileY, or,
+// when Body is set, another body it is standing on after falling onto it at
+// Speed, or, when Block is set, something solid that stopped it.
+type Contact struct {
+	TileX, TileY int
+	Hazard       base.TileHazard
+	Body         *Body
+	Speed        float64
+	Block        Block
+}
+
+// Block is which way something solid stopped a body.
+type Block int
+
+const (
+	NotBlocked Block = iota
+	// Wall is a solid tile or a solid platform the body ran into sideways.
+	// The edges of the world hold the body in without being walls.
+	Wall
+	// Floor is whatever the body stands on: a tile, a slope, a platform or
+	// another body's head.
+	Floor
+	// Ceiling is a solid tile or a solid platform the body bumped its head
+	// on.
+	Ceiling
+)
+
+// IsHazard tells whether the contact is with a hazard tile.
+func (c Contact) IsHazard() bool {
+	return c.Body == nil && c.Block == NotBlocked
+}
+
+// Step moves the body one tick and returns what it ends up touching. What
+// that does to it is up to the caller.
+func (w *World) Step(p PhysicalBody) []Contact {
+	self := p.PhysicsBody()
 	x, y := p.Position()
 	vx, vy := p.Velocity()
 	wid, h := p.Size()
 	weight := p.GetWeight()
+	dropThrough := p.DropsThrough()
+	p.SetDropThrough(false)
+	if p.IsOnGround() {
+		x, y = w.ride(x, y, wid, h)
+	}
+	onSlope := w.onSlope(x, y+h)
 
 	vy += constants.Gravity * weight
+	fallSpeed := vy
 
 	newX := x + vx
 	newY := y + vy
+	var contacts []Contact
 
 	if newX+wid/2 < 0 || newX-wid/2 > w.Width || newY - h < 0 || newY + h > w.Height {
 		p.Die()
@@ -38,27 +85,152 @@ func (w *World) Step(p PhysicalBody) {
 			tileX := int((newX + math.Copysign(wid/2, vx)) / constants.TileSize)
 			tileY1 := int(y / constants.TileSize)
 			tileY2 := int((y + h - 1) / constants.TileSize)
+			if onSlope {
+				// The feet are inside the row a slope leads onto, which
+				// is no wall to a body walking up it.
+				tileY2--
+			}
 
+			blocked := false
 			for ty := tileY1; ty <= tileY2; ty++ {
-				if w.Tiles.IsSolid(tileX, ty) {
-					newX = x
+				if w.Tiles.IsSolid(tileX, ty) || w.slopeWall(tileX, ty, x, y+h, wid, vx) {
+					blocked = true
 					break
 				}
 			}
+			if blocked || w.platformBlocks(x, newX, y, wid, h) {
+				newX = x
+				contacts = append(contacts, Contact{Block: Wall})
+			}
 		}
 	}
 
 	var onGround bool
-	newY, onGround = resolveVerticalCollision(
-		w, y, newX, newY, wid, h, &vy,
-	)
+	if slopeY, ok := w.landOnSlope(y, newX, newY, h, vx, vy, p.IsOnGround()); ok {
+		newY, vy, onGround = slopeY, 0, true
+	} else {
+		rising := vy < 0
+		newY, onGround = resolveVerticalCollision(
+			w, self, y, newX, newY, wid, h, &vy, dropThrough,
+		)
+		if rising && vy == 0 {
+			contacts = append(contacts, Contact{Block: Ceiling})
+		}
+		if onSlope && !onGround && vy > 0 {
+			// Off the foot of a slope the floor can be further down
+			// than one tick of falling reaches.
+			snapVY := vy
+			snapY, landed := resolveVerticalCollision(
+				w, self, y, newX, newY+math.Abs(vx)+1, wid, h, &snapVY, dropThrough,
+			)
+			if landed {
+				newY, vy, onGround = snapY, 0, true
+			}
+		}
+	}
 
 	p.SetOnGround(onGround)
 	p.SetPosition(newX, newY)
 	p.SetVelocity(vx, vy)
+
+	if onGround {
+		contacts = append(contacts, Contact{Block: Floor})
+	}
+	contacts = append(contacts, w.hazards(newX, newY, wid, h)...)
+	if onGround {
+		if below := w.bodyBelow(self, newX, newY+h, wid); below != nil {
+			contacts = append(contacts, Contact{Body: below, Speed: fallSpeed})
+		}
+	}
+	return contacts
+}
+
+// hazards are the hazard tiles overlapping the body at x, y.
+func (w *World) hazards(x, y, wid, h float64) []Contact {
+	var contacts []Contact
+	tileX1 := int((x - wid/2) / constants.TileSize)
+	tileX2 := int((x + wid/2 - 1) / constants.TileSize)
+	tileY1 := int(y / constants.TileSize)
+	tileY2 := int((y + h - 1) / constants.TileSize)
+	for ty := tileY1; ty <= tileY2; ty++ {
+		for tx := tileX1; tx <= tileX2; tx++ {
+			if hazard, ok := w.Tiles.HazardAt(tx, ty); ok {
+				contacts = append(contacts, Contact{TileX: tx, TileY: ty, Hazard: hazard})
+			}
+		}
+	}
+	return contacts
+}
+
+// onSlope tells whether feet at x, feet stand on the floor of a slope.
+func (w *World) onSlope(x, feet float64) bool {
+	_, _, ok := w.slopeUnder(x, feet)
+	return ok
+}
+
+// slopeUnder is the slope tile whose floor feet at x, feet stand on.
+func (w *World) slopeUnder(x, feet float64) (int, int, bool) {
+	tx := int(x / constants.TileSize)
+	for _, ty := range []int{int((feet - 1) / constants.TileSize), int(feet / constants.TileSize)} {
+		if surface, ok := w.Tiles.SlopeSurface(tx, ty, x); ok && math.Abs(surface-feet) <= 1 {
+			return tx, ty, true
+		}
+	}
+	return 0, 0, false
+}
+
+// slopeWall tells whether the slope tile at tx, ty stops a body at x with its
+// feet at feet walking into it going vx. The tile is solid below its floor,
+// so the side it is entered from is a wall up to the height of the floor
+// there. A body walking along a slope has its feet where that slope's floor
+// meets the tile, so it walks on from one slope onto the next. Once the body
+// is over the tile, its middle decides where it stands, as in landOnSlope.
+func (w *World) slopeWall(tx, ty int, x, feet, wid, vx float64) bool {
+	edge := float64(tx) * constants.TileSize
+	if vx < 0 {
+		edge += constants.TileSize
+	}
+	wall, ok := w.Tiles.SlopeSurface(tx, ty, edge)
+	if !ok || (vx > 0 && x+wid/2 > edge) || (vx < 0 && x-wid/2 < edge) {
+		return false
+	}
+	if stx, sty, ok := w.slopeUnder(x, feet); ok {
+		feet, _ = w.Tiles.SlopeSurface(stx, sty, edge)
+	}
+	return feet > wall+1
 }
 
-func resolveVerticalCollision(w *World, oldY, newX, newY, wWidth, wHeight float64, vy *float64) (float64, bool) {
+// landOnSlope puts a body whose middle is over a slope on its floor, and
+// returns the new Y for it. Only the middle counts, so the edges of the body
+// hang over whatever is beside the slope. A body walking up a slope climbs it
+// as far as it moved sideways; one on the ground walking down sticks to it
+// rather than leaving the ground every tick.
+func (w *World) landOnSlope(oldY, x, newY, h, vx, vy float64, grounded bool) (float64, bool) {
+	if vy < 0 {
+		return 0, false
+	}
+
+	reach := math.Abs(vx) + 1
+	snap := 0.0
+	if grounded {
+		snap = reach
+	}
+
+	oldFeet, newFeet := oldY+h, newY+h
+	tx := int(x / constants.TileSize)
+	for ty := int((oldFeet - reach) / constants.TileSize); ty <= int((newFeet+snap)/constants.TileSize); ty++ {
+		surface, ok := w.Tiles.SlopeSurface(tx, ty, x)
+		if ok && surface >= oldFeet-reach && surface <= newFeet+snap {
+			return surface - h, true
+		}
+	}
+	return 0, false
+}
+
+// resolveVerticalCollision stops the body on the first tile it would move
+// into. Platforms only catch a body falling onto them from above, and not
+// even then when it is dropping through.
+func resolveVerticalCollision(w *World, self *Body, oldY, newX, newY, wWidth, wHeight float64, vy *float64, dropThrough bool) (float64, bool) {
 	onGround := false
 
 	if *vy == 0 {
@@ -74,45 +246,239 @@ func resolveVerticalCollision(w *World, oldY, newX, newY, wWidth, wHeight float6
 	tileX2 := int((newX + wWidth/2) / constants.TileSize)
 
 	if stepSign > 0 {
+		// A moving platform or another body's head can be between the
+		// body and the first tile it lands on.
+		platformTop, onPlatform := w.platformBelow(oldY+wHeight, newX, newY+wHeight, wWidth, dropThrough)
+		if headTop, onHead := w.headBelow(self, oldY+wHeight, newX, newY+wHeight, wWidth); onHead && (!onPlatform || headTop < platformTop) {
+			platformTop, onPlatform = headTop, true
+		}
+		land := func(top float64) (float64, bool) {
+			if onPlatform && platformTop < top {
+				top = platformTop
+			}
+			*vy = 0
+			return top - wHeight, true
+		}
+
 		tileYStart := int((oldY + wHeight) / constants.TileSize)
 		tileYEnd   := int((newY + wHeight) / constants.TileSize)
 		for ty := tileYStart; ty <= tileYEnd; ty++ {
 			for tx := tileX1; tx <= tileX2; tx++ {
 				if w.Tiles.IsSolid(tx, ty) {
-					newY = float64(ty)*constants.TileSize - wHeight
-					*vy = 0
-					onGround = true
-					return newY, onGround
+					return land(float64(ty)*constants.TileSize)
 				}
 
-				if w.Tiles.IsPlatform(tx, ty) {
+				if !dropThrough && w.Tiles.IsPlatform(tx, ty) {
 					tileTop := float64(ty * constants.TileSize)
 					if oldY+wHeight <= tileTop {
-						newY = tileTop - wHeight
-						*vy = 0
-						onGround = true
-						return newY, onGround
+						return land(tileTop)
 					}
 				}
 			}
 		}
+		if onPlatform {
+			return land(platformTop)
+		}
 	} else {
+		platformBottom, underPlatform := w.platformAbove(oldY, newX, newY, wWidth)
+		hitCeiling := func(bottom float64) (float64, bool) {
+			if underPlatform && platformBottom > bottom {
+				bottom = platformBottom
+			}
+			*vy = 0
+			return bottom, onGround
+		}
+
 		tileYStart := int(newY / constants.TileSize)
 		tileYEnd := int(oldY / constants.TileSize)
 		for ty := tileYEnd; ty >= tileYStart; ty-- {
 			for tx := tileX1; tx <= tileX2; tx++ {
 				if w.Tiles.IsSolid(tx, ty){
-					newY = float64(ty+1) * constants.TileSize
-					*vy = 0
-					return newY, onGround
+					return hitCeiling(float64(ty+1) * constants.TileSize)
+				}
+				// A slope is solid below its floor, so a head coming
+				// up from under the tile bumps into its bottom.
+				if bottom := float64(ty+1) * constants.TileSize; w.Tiles.IsSlope(tx, ty) && oldY >= bottom {
+					return hitCeiling(bottom)
 				}
 			}
 		}
+		if underPlatform {
+			return hitCeiling(platformBottom)
+		}
 	}
 
 	return newY, onGround
 }
 
+// MovePlatforms takes every moving platform one tick along its path. It goes
+// before the bodies step, so they ride along with the platforms under them.
+func (w *World) MovePlatforms() {
+	for _, p := range w.Platforms {
+		p.Move()
+	}
+}
+
+// ride moves a body standing on a platform as far as the platform moved in
+// the last tick. It is never carried sideways into a wall.
+func (w *World) ride(x, y, wid, h float64) (float64, float64) {
+	for _, p := range w.Platforms {
+		top := p.Y - p.DY
+		if math.Abs(y+h-top) > 1 || !p.overlapsX(x-p.DX, wid) {
+			continue
+		}
+		if !w.overlapsSolid(x+p.DX, y+p.DY, wid, h) {
+			x += p.DX
+		}
+		return x, y + p.DY
+	}
+	return x, y
+}
+
+func (w *World) overlapsSolid(x, y, wid, h float64) bool {
+	tileX1 := int((x - wid/2) / constants.TileSize)
+	tileX2 := int((x + wid/2 - 1) / constants.TileSize)
+	tileY1 := int(y / constants.TileSize)
+	tileY2 := int((y + h - 1) / constants.TileSize)
+	for ty := tileY1; ty <= tileY2; ty++ {
+		for tx := tileX1; tx <= tileX2; tx++ {
+			if w.Tiles.IsSolid(tx, ty) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// platformBlocks tells whether a solid platform stands in the way of a body
+// moving from x to newX. One it is already inside doesn't, so a platform
+// moving into a body never leaves it stuck.
+func (w *World) platformBlocks(x, newX, y, wid, h float64) bool {
+	for _, p := range w.Platforms {
+		if p.Solid && p.overlaps(newX, y, wid, h) && !p.overlaps(x, y, wid, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// platformBelow is the top of the highest platform that feet falling from
+// oldFeet to newFeet land on. A platform that rose into the feet this tick
+// still catches them.
+func (w *World) platformBelow(oldFeet, x, newFeet, wid float64, dropThrough bool) (float64, bool) {
+	top, found := 0.0, false
+	for _, p := range w.Platforms {
+		if dropThrough && !p.Solid {
+			continue
+		}
+		if !p.overlapsX(x, wid) || oldFeet > p.Y+max(-p.DY, 0) || newFeet < p.Y {
+			continue
+		}
+		if !found || p.Y < top {
+			top, found = p.Y, true
+		}
+	}
+	return top, found
+}
+
+// collides tells whether b is one of the bodies that collide with each other.
+func (w *World) collides(b *Body) bool {
+	return slices.Contains(w.Bodies, b)
+}
+
+// headBelow is the top of the highest colliding body that self, falling from
+// oldFeet to newFeet, lands on.
+func (w *World) headBelow(self *Body, oldFeet, x, newFeet, wid float64) (float64, bool) {
+	if !w.collides(self) {
+		return 0, false
+	}
+	top, found := 0.0, false
+	for _, b := range w.Bodies {
+		if b == self || !overlapsX(x, wid, b) || oldFeet > b.Y || newFeet < b.Y {
+			continue
+		}
+		if !found || b.Y < top {
+			top, found = b.Y, true
+		}
+	}
+	return top, found
+}
+
+// bodyBelow is the colliding body self stands on with its feet at feet.
+func (w *World) bodyBelow(self *Body, x, feet, wid float64) *Body {
+	if !w.collides(self) {
+		return nil
+	}
+	for _, b := range w.Bodies {
+		if b != self && math.Abs(b.Y-feet) < 1e-6 && overlapsX(x, wid, b) {
+			return b
+		}
+	}
+	return nil
+}
+
+func overlapsX(x, wid float64, b *Body) bool {
+	return x+wid/2 > b.X-b.Width/2 && x-wid/2 < b.X+b.Width/2
+}
+
+// PushBodies moves apart every two colliding bodies that stand on the ground
+// overlapping each other, each going half the way, so someone walking into
+// someone else pushes them along at half the speed. A body with a wall behind
+// it stays put and the other one goes all the way. Bodies in the same place
+// are pushed apart in the order they are in Bodies, the first going left.
+func (w *World) PushBodies() {
+	for i, a := range w.Bodies {
+		for _, b := range w.Bodies[i+1:] {
+			if !a.OnGround || !b.OnGround || a.Y >= b.Y+b.Height || b.Y >= a.Y+a.Height {
+				continue
+			}
+			overlap := (a.Width+b.Width)/2 - math.Abs(a.X-b.X)
+			if overlap <= 0 {
+				continue
+			}
+			dir := -1.0
+			if a.X > b.X {
+				dir = 1
+			}
+			if !w.push(a, overlap/2*dir) {
+				w.push(b, -overlap*dir)
+			} else if !w.push(b, -overlap/2*dir) {
+				w.push(a, overlap/2*dir)
+			}
+		}
+	}
+}
+
+// push moves b dx sideways unless that puts it in a wall, and tells whether
+// it did.
+func (w *World) push(b *Body, dx float64) bool {
+	x := b.X + dx
+	if x-b.Width/2 < 0 || x+b.Width/2 > w.Width || w.overlapsSolid(x, b.Y, b.Width, b.Height) {
+		return false
+	}
+	b.X = x
+	return true
+}
+
+// platformAbove is the bottom of the lowest solid platform a head rising from
+// oldY to newY bumps into.
+func (w *World) platformAbove(oldY, x, newY, wid float64) (float64, bool) {
+	bottom, found := 0.0, false
+	for _, p := range w.Platforms {
+		if !p.Solid || !p.overlapsX(x, wid) {
+			continue
+		}
+		pBottom := p.Y + p.Height
+		if oldY < pBottom-max(p.DY, 0) || newY > pBottom {
+			continue
+		}
+		if !found || pBottom > bottom {
+			bottom, found = pBottom, true
+		}
+	}
+	return bottom, found
+}
+
 
 func (w *World) UpdateVirtualBounds(cam *base.Camera) {
 	w.VirtualBorderLeftX = cam.X - float64(cam.Width)/2
@@ -134,4 +500,6 @@ func (w *World) Clear() {
 	w.VirtualBorderRightX = 0
 	w.Width = 0
 	w.Height = 0
+	w.Platforms = nil
+	w.Bodies = nil
 }
\ No newline at end of file
@@ -0,0 +1,512 @@
+package physics
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+)
+
+const (
+	testSolid    = 2
+	testPlatform = 21
+	testHazard   = 39
+	testUp       = 1
+	testDown     = 3
+)
+
+// addSlopes gives w 45 degree slopes going up and down to the right.
+func addSlopes(w *World) {
+	w.Tiles.AddSlope(testUp, base.TileSlope{Left: 0, Right: 1})
+	w.Tiles.AddSlope(testDown, base.TileSlope{Left: 1, Right: 0})
+}
+
+// testBody is the least a body needs to be stepped through a world.
+type testBody struct {
+	*Body
+	dead bool
+}
+
+func (b *testBody) Die() {
+	b.dead = true
+}
+
+// newTestWorld is a box of empty tiles with a solid floor along the bottom
+// row and the tiles of hazardRow above it set to hazards.
+func newTestWorld(width, height, hazardRow int, hazard base.TileHazard) *World {
+	tiles := base.NewTileMap(width, height, constants.TileSize)
+	tiles.AddTileType(testSolid, base.Solid)
+	tiles.AddHazard(testHazard, hazard)
+	for x := range width {
+		tiles.SetTile(x, height-1, testSolid)
+		tiles.SetTile(x, hazardRow, testHazard)
+	}
+	return NewWorld(tiles)
+}
+
+func TestStepReportsHazardEveryTick(t *testing.T) {
+	hazard := base.TileHazard{Damage: 3, KnockbackY: -4}
+	w := newTestWorld(5, 6, 4, hazard)
+	b := &testBody{Body: &Body{X: 2.5 * constants.TileSize, Y: constants.TileSize, Width: 12, Height: 26, Weight: 1}}
+
+	touching := 0
+	for tick := range 120 {
+		var contacts []Contact
+		for _, c := range w.Step(b) {
+			if c.IsHazard() {
+				contacts = append(contacts, c)
+			}
+		}
+		if b.Y+b.Height <= 4*constants.TileSize {
+			if len(contacts) != 0 {
+				t.Fatalf("tick %d: %d contacts above the hazard row", tick, len(contacts))
+			}
+			continue
+		}
+
+		touching++
+		if len(contacts) != 1 {
+			t.Fatalf("tick %d: %d contacts inside the hazard row, want 1", tick, len(contacts))
+		}
+		c := contacts[0]
+		if c.TileX != 2 || c.TileY != 4 || c.Hazard != hazard || c.Body != nil {
+			t.Fatalf("tick %d: contact = %+v", tick, c)
+		}
+	}
+
+	if !b.OnGround || b.Y+b.Height != 5*constants.TileSize {
+		t.Errorf("body at y %v, on ground %v; want it standing on the floor", b.Y, b.OnGround)
+	}
+	if touching < 100 {
+		t.Errorf("touched the hazard on %d ticks, want every tick from landing in it", touching)
+	}
+	if b.dead {
+		t.Error("body died")
+	}
+}
+
+// blocks are the kinds of blocking contacts among contacts.
+func blocks(contacts []Contact) []Block {
+	var kinds []Block
+	for _, c := range contacts {
+		if c.Block != NotBlocked {
+			kinds = append(kinds, c.Block)
+		}
+	}
+	return kinds
+}
+
+func TestStepReportsBlocks(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(w *World)
+		body   Body
+		blocks []Block
+	}{
+		{
+			name:   "wall",
+			setup:  func(w *World) { w.Tiles.SetTile(3, 3, testSolid) },
+			body:   Body{X: 3*constants.TileSize - 6, Y: 3 * constants.TileSize, VX: 4, Width: 12, Height: 20},
+			blocks: []Block{Wall},
+		},
+		{
+			name:   "floor",
+			body:   Body{X: 80, Y: 4*constants.TileSize - 20, VY: 2, Width: 12, Height: 20, Weight: 1},
+			blocks: []Block{Floor},
+		},
+		{
+			name:   "ceiling",
+			setup:  func(w *World) { w.Tiles.SetTile(2, 1, testSolid) },
+			body:   Body{X: 80, Y: 2*constants.TileSize + 2, VY: -6, Width: 12, Height: 20, Weight: 1},
+			blocks: []Block{Ceiling},
+		},
+		{
+			name:   "platform tile",
+			setup:  func(w *World) { w.Tiles.SetTile(2, 3, testPlatform) },
+			body:   Body{X: 80, Y: 3*constants.TileSize - 22, VY: 4, Width: 12, Height: 20, Weight: 1},
+			blocks: []Block{Floor},
+		},
+		{
+			name: "moving platform",
+			setup: func(w *World) {
+				p := NewPlatform(32, 16, []Point{{X: 110, Y: 96}, {X: 140, Y: 96}}, 1, PingPong)
+				p.Solid = true
+				w.Platforms = append(w.Platforms, p)
+			},
+			body:   Body{X: 84, Y: 3 * constants.TileSize, VX: 6, Width: 12, Height: 20},
+			blocks: []Block{Wall},
+		},
+		{
+			name: "slope from below",
+			setup: func(w *World) {
+				addSlopes(w)
+				w.Tiles.SetTile(2, 1, testUp)
+			},
+			body:   Body{X: 80, Y: 2*constants.TileSize + 2, VY: -6, Width: 12, Height: 20, Weight: 1},
+			blocks: []Block{Ceiling},
+		},
+		{
+			name: "tall side of a slope",
+			setup: func(w *World) {
+				addSlopes(w)
+				w.Tiles.SetTile(3, 3, testDown)
+			},
+			body:   Body{X: 3*constants.TileSize - 6, Y: 4*constants.TileSize - 20, VX: 4, Width: 12, Height: 20},
+			blocks: []Block{Wall},
+		},
+		{
+			name: "low side of a slope",
+			setup: func(w *World) {
+				addSlopes(w)
+				w.Tiles.SetTile(3, 3, testUp)
+			},
+			body: Body{X: 3*constants.TileSize - 6, Y: 4*constants.TileSize - 20, VX: 4, Width: 12, Height: 20},
+		},
+		{
+			name: "edge of the world",
+			body: Body{X: 5*constants.TileSize - 8, Y: 3 * constants.TileSize, VX: 4, Width: 12, Height: 20},
+		},
+		{
+			name: "open air",
+			body: Body{X: 80, Y: 2 * constants.TileSize, VX: 3, VY: -2, Width: 12, Height: 20, Weight: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(5, 5, 0, base.TileHazard{})
+			w.Tiles.AddTileType(testPlatform, base.Platform)
+			// No hazards in the way of these.
+			for x := range 5 {
+				w.Tiles.SetTile(x, 0, 0)
+			}
+			if tt.setup != nil {
+				tt.setup(w)
+			}
+			body := tt.body
+			got := blocks(w.Step(&testBody{Body: &body}))
+			if !slices.Equal(got, tt.blocks) {
+				t.Errorf("blocks = %v, want %v", got, tt.blocks)
+			}
+		})
+	}
+}
+
+func TestSlopeBottomIsCeiling(t *testing.T) {
+	w := newTestWorld(5, 6, 0, base.TileHazard{})
+	addSlopes(w)
+	w.Tiles.SetTile(2, 0, 0)
+	w.Tiles.SetTile(2, 2, testDown)
+	b := &testBody{Body: &Body{X: 2.5 * constants.TileSize, Y: 3*constants.TileSize + 4, VY: -10, Width: 12, Height: 26, Weight: 1}}
+
+	w.Step(b)
+	if b.Y != 3*constants.TileSize || b.VY != 0 {
+		t.Errorf("head at y %v going %v, want it stopped under the slope at %v", b.Y, b.VY, 3*constants.TileSize)
+	}
+}
+
+// walkOver walks a body standing on the floor of w right at 2 pixels a tick
+// until it is past column last, and returns the highest its feet got. Every
+// tick the body has to stay on the ground and must never be stopped.
+func walkOver(t *testing.T, w *World, last int) float64 {
+	t.Helper()
+
+	floor := w.Height - constants.TileSize
+	b := &testBody{Body: &Body{X: constants.TileSize, Y: floor - 26, VX: 2, Width: 12, Height: 26, Weight: 1, OnGround: true}}
+	highest := floor
+	for tick := 0; b.X < float64(last+1)*constants.TileSize+b.Width; tick++ {
+		if tick > 1000 {
+			t.Fatalf("stuck at x %v", b.X)
+		}
+		x := b.X
+		if got := blocks(w.Step(b)); !slices.Equal(got, []Block{Floor}) {
+			t.Fatalf("tick %d at x %v: blocks = %v, want to stand on the floor", tick, x, got)
+		}
+		if b.X != x+2 {
+			t.Fatalf("tick %d: x went from %v to %v", tick, x, b.X)
+		}
+		highest = min(highest, b.Y+b.Height)
+	}
+	if b.Y+b.Height != floor {
+		t.Errorf("feet at %v past the slopes, want back on the floor at %v", b.Y+b.Height, floor)
+	}
+	if b.dead {
+		t.Error("body died")
+	}
+	return highest
+}
+
+func TestWalkOverSlopes(t *testing.T) {
+	const (
+		upLow    = 5
+		upHigh   = 6
+		downHigh = 7
+		downLow  = 8
+	)
+	tests := []struct {
+		name string
+		// rows are the tiles of the hill, from the top row down to the
+		// one on the floor.
+		rows [][]int
+		peak float64
+	}{
+		{
+			name: "45 degrees",
+			rows: [][]int{
+				{0, 0, 0, 0, testUp, testDown, 0, 0, 0, 0},
+				{0, 0, 0, testUp, testSolid, testSolid, testDown, 0, 0, 0},
+			},
+			peak: 5 * constants.TileSize,
+		},
+		{
+			name: "22.5 degrees",
+			rows: [][]int{
+				{0, 0, 0, upLow, upHigh, downHigh, downLow, 0, 0, 0},
+			},
+			peak: 6 * constants.TileSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(10, 8, 0, base.TileHazard{})
+			addSlopes(w)
+			w.Tiles.AddSlope(upLow, base.TileSlope{Left: 0, Right: 0.5})
+			w.Tiles.AddSlope(upHigh, base.TileSlope{Left: 0.5, Right: 1})
+			w.Tiles.AddSlope(downHigh, base.TileSlope{Left: 1, Right: 0.5})
+			w.Tiles.AddSlope(downLow, base.TileSlope{Left: 0.5, Right: 0})
+			for x := range 10 {
+				w.Tiles.SetTile(x, 0, 0)
+			}
+			top := 7 - len(tt.rows)
+			for y, row := range tt.rows {
+				for x, id := range row {
+					w.Tiles.SetTile(x, top+y, id)
+				}
+			}
+
+			if highest := walkOver(t, w, 7); highest != tt.peak {
+				t.Errorf("feet got up to %v, want over the top of the hill at %v", highest, tt.peak)
+			}
+		})
+	}
+}
+
+// newPlatformWorld is an empty box of tiles with a solid floor along the
+// bottom and p in it.
+func newPlatformWorld(p *Platform) *World {
+	w := newTestWorld(10, 8, 0, base.TileHazard{})
+	for x := range 10 {
+		w.Tiles.SetTile(x, 0, 0)
+	}
+	w.Platforms = append(w.Platforms, p)
+	return w
+}
+
+// standOn is a body standing in the middle of p.
+func standOn(p *Platform) *testBody {
+	return &testBody{Body: &Body{X: p.X, Y: p.Y - 26, Width: 12, Height: 26, Weight: 1, OnGround: true}}
+}
+
+func TestPlatformCarriesBody(t *testing.T) {
+	tests := []struct {
+		name string
+		path []Point
+	}{
+		{name: "sideways", path: []Point{{X: 100, Y: 128}, {X: 200, Y: 128}}},
+		{name: "up and down", path: []Point{{X: 100, Y: 192}, {X: 100, Y: 96}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlatform(48, 8, tt.path, 2, PingPong)
+			w := newPlatformWorld(p)
+			b := standOn(p)
+			offset := b.X - p.X
+
+			// Twice along the path and back, turning at both ends.
+			for tick := range 200 {
+				w.MovePlatforms()
+				got := blocks(w.Step(b))
+				if !slices.Equal(got, []Block{Floor}) || b.Y+b.Height != p.Y || b.X-p.X != offset {
+					t.Fatalf("tick %d: body at %v, %v with blocks %v, platform at %v, %v; want it riding along",
+						tick, b.X, b.Y, got, p.X, p.Y)
+				}
+			}
+		})
+	}
+}
+
+func TestPlatformBlocksBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		solid  bool
+		body   Body
+		blocks []Block
+		// want is where the body is after one tick.
+		wantX, wantY float64
+	}{
+		{
+			name:   "solid from the side",
+			solid:  true,
+			body:   Body{X: 70, Y: 120, VX: 4, Width: 12, Height: 26},
+			blocks: []Block{Wall},
+			wantX:  70, wantY: 120,
+		},
+		{
+			name:   "solid from below",
+			solid:  true,
+			body:   Body{X: 100, Y: 140, VY: -8, Width: 12, Height: 26},
+			blocks: []Block{Ceiling},
+			wantX:  100, wantY: 136,
+		},
+		{
+			name:  "one-way from the side",
+			body:  Body{X: 70, Y: 120, VX: 4, Width: 12, Height: 26},
+			wantX: 74, wantY: 120,
+		},
+		{
+			name:  "one-way from below",
+			body:  Body{X: 100, Y: 140, VY: -8, Width: 12, Height: 26},
+			wantX: 100, wantY: 132,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A platform standing still from 76 to 124 across and from
+			// 128 to 136 down.
+			p := NewPlatform(48, 8, []Point{{X: 100, Y: 128}}, 0, PingPong)
+			p.Solid = tt.solid
+			w := newPlatformWorld(p)
+			body := tt.body
+			b := &testBody{Body: &body}
+
+			got := blocks(w.Step(b))
+			if !slices.Equal(got, tt.blocks) {
+				t.Errorf("blocks = %v, want %v", got, tt.blocks)
+			}
+			if b.X != tt.wantX || b.Y != tt.wantY {
+				t.Errorf("body at %v, %v, want %v, %v", b.X, b.Y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestJumpUpThroughOneWayPlatform(t *testing.T) {
+	p := NewPlatform(48, 8, []Point{{X: 100, Y: 128}}, 0, PingPong)
+	w := newPlatformWorld(p)
+	b := &testBody{Body: &Body{X: 100, Y: 140, VY: -8, Width: 12, Height: 26, Weight: 1}}
+
+	for range 60 {
+		w.Step(b)
+	}
+	if !b.OnGround || b.Y+b.Height != p.Y {
+		t.Errorf("feet at %v, on ground %v; want it standing on the platform at %v", b.Y+b.Height, b.OnGround, p.Y)
+	}
+}
+
+// newCrowdWorld is an empty box of tiles with a solid floor along the
+// bottom, and a body standing on it at every x, all colliding with each
+// other.
+func newCrowdWorld(xs ...float64) (*World, []*testBody) {
+	w := newTestWorld(10, 6, 0, base.TileHazard{})
+	for x := range 10 {
+		w.Tiles.SetTile(x, 0, 0)
+	}
+	var bodies []*testBody
+	for _, x := range xs {
+		b := &testBody{Body: &Body{X: x, Y: 5*constants.TileSize - 26, Width: 12, Height: 26, Weight: 1, OnGround: true}}
+		bodies = append(bodies, b)
+		w.Bodies = append(w.Bodies, b.Body)
+	}
+	return w, bodies
+}
+
+// stepCrowd steps every body and then pushes them apart, the way the
+// simulation does.
+func stepCrowd(w *World, bodies []*testBody) {
+	for _, b := range bodies {
+		w.Step(b)
+	}
+	w.PushBodies()
+}
+
+func TestPushBodiesAtHalfSpeed(t *testing.T) {
+	w, bodies := newCrowdWorld(100, 120)
+	pusher, pushed := bodies[0], bodies[1]
+	pusher.VX = 2
+
+	for range 4 {
+		stepCrowd(w, bodies)
+	}
+	// Touching now; from here on both go at half the pusher's speed.
+	for tick := range 20 {
+		x1, x2 := pusher.X, pushed.X
+		stepCrowd(w, bodies)
+		if pusher.X-x1 != 1 || pushed.X-x2 != 1 {
+			t.Fatalf("tick %d: moved %v and %v, want 1 each", tick, pusher.X-x1, pushed.X-x2)
+		}
+		if gap := pushed.X - pusher.X; gap != 12 {
+			t.Fatalf("tick %d: %v apart, want them touching at 12", tick, gap)
+		}
+	}
+}
+
+func TestPushBodiesAgainstWall(t *testing.T) {
+	w, bodies := newCrowdWorld(100, 154)
+	w.Tiles.SetTile(5, 4, testSolid)
+	pusher, pushed := bodies[0], bodies[1]
+	pusher.VX = 2
+
+	for range 40 {
+		stepCrowd(w, bodies)
+	}
+	if pushed.X != 154 {
+		t.Errorf("body against the wall moved to %v", pushed.X)
+	}
+	if pusher.X != 142 {
+		t.Errorf("pusher at %v, want it stopped against the other at 142", pusher.X)
+	}
+}
+
+func TestPushBodiesInSamePlace(t *testing.T) {
+	w, bodies := newCrowdWorld(100, 100)
+
+	w.PushBodies()
+	if bodies[0].X != 94 || bodies[1].X != 106 {
+		t.Errorf("pushed apart to %v and %v, want the first left at 94 and the second right at 106", bodies[0].X, bodies[1].X)
+	}
+}
+
+func TestPushBodiesInFixedOrder(t *testing.T) {
+	// A pile of bodies is pushed apart the same way every time, decided by
+	// their order in Bodies alone.
+	run := func(swap bool) []float64 {
+		w, bodies := newCrowdWorld(100, 100, 100, 103)
+		if swap {
+			w.Bodies[0], w.Bodies[1] = w.Bodies[1], w.Bodies[0]
+		}
+		bodies[3].VX = -1
+		for range 30 {
+			stepCrowd(w, bodies)
+		}
+		var xs []float64
+		for _, b := range bodies {
+			xs = append(xs, b.X)
+		}
+		return xs
+	}
+
+	want := run(false)
+	for range 10 {
+		if got := run(false); !slices.Equal(got, want) {
+			t.Fatalf("pushed apart to %v, then to %v", want, got)
+		}
+	}
+	swapped := run(true)
+	if swapped[0] != want[1] || swapped[1] != want[0] || !slices.Equal(swapped[2:], want[2:]) {
+		t.Errorf("with the first two swapped in Bodies pushed apart to %v, want %v with the first two swapped", swapped, want)
+	}
+}
@@ -1,7 +1,5 @@
 package base
 
-import "github.com/hajimehoshi/ebiten/v2"
-
 type TileType int
 
 const (
@@ -9,19 +7,39 @@ const (
 	Solid
 	Platform
 	Hazard
+	Slope
 )
 
+// TileHazard is what a hazard tile does to an actor touching it: Damage every
+// tick of contact, a push of KnockbackX away from the tile and KnockbackY
+// (negative is up) whenever it starts hurting, or, with Kill, death on the
+// spot.
+type TileHazard struct {
+	Damage     int
+	KnockbackX float64
+	KnockbackY float64
+	Kill       bool
+}
+
+// TileSlope is the floor of a slope tile, given by its height at the left
+// and the right edge, from 0 at the bottom of the tile to 1 at the top. The
+// tile is solid below the floor and empty above it.
+type TileSlope struct {
+	Left  float64
+	Right float64
+}
+
 type Tile struct {
-	ID    int
-	Type  TileType
-	Image *ebiten.Image
+	ID   int
+	Type TileType
 }
 
 type TileMap struct {
 	Width, Height int
 	Tiles         [][]*Tile
-	Textures      map[int]*ebiten.Image
 	TileTypes     map[int]TileType
+	Hazards       map[int]TileHazard
+	Slopes        map[int]TileSlope
 	TileSize      int
 }
 
@@ -30,7 +48,7 @@ func NewTileMap(width, height, tileSize int) *TileMap {
 	for y := range tiles {
 		tiles[y] = make([]*Tile, width)
 		for x := range tiles[y] {
-			tiles[y][x] = &Tile{ID: 0, Type: Empty, Image: nil}
+			tiles[y][x] = &Tile{ID: 0, Type: Empty}
 		}
 	}
 
@@ -38,24 +56,33 @@ func NewTileMap(width, height, tileSize int) *TileMap {
 		Width:     width,
 		Height:    height,
 		Tiles:     tiles,
-		Textures:  make(map[int]*ebiten.Image),
 		TileTypes: make(map[int]TileType),
+		Hazards:   make(map[int]TileHazard),
+		Slopes:    make(map[int]TileSlope),
 		TileSize:  tileSize,
 	}
 }
 
-func (m *TileMap) AddTileType(id int, t TileType, img *ebiten.Image) {
-	m.Textures[id] = img
+func (m *TileMap) AddTileType(id int, t TileType) {
 	m.TileTypes[id] = t
 }
 
+func (m *TileMap) AddHazard(id int, h TileHazard) {
+	m.TileTypes[id] = Hazard
+	m.Hazards[id] = h
+}
+
+func (m *TileMap) AddSlope(id int, s TileSlope) {
+	m.TileTypes[id] = Slope
+	m.Slopes[id] = s
+}
+
 func (m *TileMap) SetTile(x, y, id int) {
 	if x < 0 || x >= m.Width || y < 0 || y >= m.Height {
 		return
 	}
 	m.Tiles[y][x].ID = id
 	m.Tiles[y][x].Type = m.TileTypes[id]
-	m.Tiles[y][x].Image = m.Textures[id]
 }
 
 func (m *TileMap) IsSolid(tx, ty int) bool {
@@ -74,22 +101,34 @@ func (m *TileMap) IsPlatform(tx, ty int) bool {
 	return tile.Type == Platform
 }
 
-func (m *TileMap) Draw(screen *ebiten.Image, cam *Camera) {
-	camX, camY := cam.TopLeft()
-
-	for y := 0; y < m.Height; y++ {
-		for x := 0; x < m.Width; x++ {
-			tile := m.Tiles[y][x]
-			if tile.ID == 0 || tile.Image == nil {
-				continue
-			}
-
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(
-				float64(x*m.TileSize)-camX,
-				float64(y*m.TileSize)-camY,
-			)
-			screen.DrawImage(tile.Image, op)
-		}
+func (m *TileMap) HazardAt(tx, ty int) (TileHazard, bool) {
+	if ty < 0 || ty >= m.Height || tx < 0 || tx >= m.Width {
+		return TileHazard{}, false
+	}
+	tile := m.Tiles[ty][tx]
+	if tile.Type != Hazard {
+		return TileHazard{}, false
+	}
+	return m.Hazards[tile.ID], true
+}
+
+func (m *TileMap) IsSlope(tx, ty int) bool {
+	if ty < 0 || ty >= m.Height || tx < 0 || tx >= m.Width {
+		return false
+	}
+	tile := m.Tiles[ty][tx]
+	return tile.Type == Slope
+}
+
+// SlopeSurface is the height in pixels of the floor of the slope tile at tx,
+// ty, where it passes over x.
+func (m *TileMap) SlopeSurface(tx, ty int, x float64) (float64, bool) {
+	if !m.IsSlope(tx, ty) {
+		return 0, false
 	}
+	slope := m.Slopes[m.Tiles[ty][tx].ID]
+	size := float64(m.TileSize)
+	along := min(max((x-float64(tx)*size)/size, 0), 1)
+	height := slope.Left + (slope.Right-slope.Left)*along
+	return (float64(ty) + 1 - height) * size, true
 }
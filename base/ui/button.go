@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// Button calls OnPress when it is confirmed.
+type Button struct {
+	point
+	focus
+	Text    string
+	OnPress func()
+}
+
+func NewButton(text string, onPress func()) *Button {
+	return &Button{Text: text, OnPress: onPress}
+}
+
+func (b *Button) Update() {}
+
+func (b *Button) CanFocus() bool {
+	return true
+}
+
+func (b *Button) Handle(nav Nav) bool {
+	if !nav.Confirm {
+		return false
+	}
+	if b.OnPress != nil {
+		b.OnPress()
+	}
+	return true
+}
+
+func (b *Button) Size() (w, h int) {
+	return textSize(marker + b.Text)
+}
+
+func (b *Button) Draw(screen *ebiten.Image) {
+	b.drawMarker(screen, b.x, b.y)
+	ebitenutil.DebugPrintAt(screen, b.Text, b.x+len(marker)*charW, b.y)
+}
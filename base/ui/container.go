@@ -0,0 +1,185 @@
+package ui
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+type Direction int
+
+const (
+	Vertical Direction = iota
+	Horizontal
+)
+
+// Container lays its children out in a row or a column, Spacing pixels
+// apart, and moves the focus between the ones that can take it: Up and Down
+// in a column, Left and Right in a row. A container is focusable itself, so
+// containers nest. Back is never used by a container; OnBack, when set on
+// the outermost one, gets whatever Back the focused widget didn't use.
+type Container struct {
+	point
+	Direction Direction
+	Spacing   int
+	Children  []Widget
+	// Wrap moves the focus from the last child back to the first and the
+	// other way round. Without it the focus is handed to the parent instead.
+	Wrap   bool
+	OnBack func()
+
+	focused bool
+	current int
+}
+
+func NewContainer(direction Direction, spacing int, children ...Widget) *Container {
+	c := &Container{Direction: direction, Spacing: spacing}
+	c.Add(children...)
+	return c
+}
+
+func (c *Container) Add(children ...Widget) *Container {
+	c.Children = append(c.Children, children...)
+	c.current = c.next(-1, 1)
+	c.layout()
+	return c
+}
+
+func (c *Container) SetPosition(x, y int) {
+	c.x, c.y = x, y
+	c.layout()
+}
+
+func (c *Container) layout() {
+	x, y := c.x, c.y
+	for _, child := range c.Children {
+		child.SetPosition(x, y)
+		w, h := child.Size()
+		if c.Direction == Vertical {
+			y += h + c.Spacing
+		} else {
+			x += w + c.Spacing
+		}
+	}
+}
+
+func (c *Container) Size() (w, h int) {
+	for i, child := range c.Children {
+		cw, ch := child.Size()
+		gap := 0
+		if i > 0 {
+			gap = c.Spacing
+		}
+		if c.Direction == Vertical {
+			w, h = max(w, cw), h+gap+ch
+		} else {
+			w, h = w+gap+cw, max(h, ch)
+		}
+	}
+	return w, h
+}
+
+// Update lets every child update and lays them out again, since the size of
+// a widget changes with its text.
+func (c *Container) Update() {
+	for _, child := range c.Children {
+		child.Update()
+	}
+	c.layout()
+}
+
+func (c *Container) Draw(screen *ebiten.Image) {
+	for _, child := range c.Children {
+		child.Draw(screen)
+	}
+}
+
+func (c *Container) CanFocus() bool {
+	return c.next(-1, 1) >= 0
+}
+
+func (c *Container) SetFocused(focused bool) {
+	c.focused = focused
+	if f := c.focusable(c.current); f != nil {
+		f.SetFocused(focused)
+	}
+}
+
+// Focus moves the focus to child i, when it can take it.
+func (c *Container) Focus(i int) {
+	if c.focusable(i) == nil {
+		return
+	}
+	c.move(i)
+}
+
+// Handle passes nav to the focused child first and moves the focus with
+// what it leaves.
+func (c *Container) Handle(nav Nav) bool {
+	if f := c.focusable(c.current); f != nil && f.Handle(nav) {
+		return true
+	}
+
+	step := 0
+	if c.Direction == Vertical {
+		step = direction(nav.Up, nav.Down)
+	} else {
+		step = direction(nav.Left, nav.Right)
+	}
+	if step != 0 {
+		if i := c.next(c.current, step); i >= 0 {
+			c.move(i)
+			return true
+		}
+	}
+
+	if nav.Back && c.OnBack != nil {
+		c.OnBack()
+		return true
+	}
+	return false
+}
+
+func direction(back, forward bool) int {
+	switch {
+	case back:
+		return -1
+	case forward:
+		return 1
+	}
+	return 0
+}
+
+func (c *Container) move(i int) {
+	if f := c.focusable(c.current); f != nil {
+		f.SetFocused(false)
+	}
+	c.current = i
+	if f := c.focusable(i); f != nil {
+		f.SetFocused(c.focused)
+	}
+}
+
+// next finds the first child after from, going step at a time, that can take
+// the focus, or -1 if there is none.
+func (c *Container) next(from, step int) int {
+	n := len(c.Children)
+	for k := 1; k <= n; k++ {
+		i := from + k*step
+		if c.Wrap {
+			i = (i%n + n) % n
+		} else if i < 0 || i >= n {
+			return -1
+		}
+		if c.focusable(i) != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Container) focusable(i int) Focusable {
+	if i < 0 || i >= len(c.Children) {
+		return nil
+	}
+	if f, ok := c.Children[i].(Focusable); ok && f.CanFocus() {
+		return f
+	}
+	return nil
+}
@@ -5,4 +5,22 @@ import "github.com/hajimehoshi/ebiten/v2"
 type Element interface {
 	Update()
 	Draw(screen *ebiten.Image)
-}
\ No newline at end of file
+}
+
+// Widget is an element a Container can lay out: it knows its own size and
+// draws wherever it is put.
+type Widget interface {
+	Element
+	Size() (w, h int)
+	SetPosition(x, y int)
+}
+
+// Focusable is a widget that can hold the focus. Handle gets the navigation
+// of every tick the widget has the focus and reports whether it used it;
+// what it leaves is up to its container, which moves the focus with it.
+type Focusable interface {
+	Widget
+	CanFocus() bool
+	SetFocused(focused bool)
+	Handle(nav Nav) bool
+}
@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// Label is text that can't take the focus. It may run over several lines.
+type Label struct {
+	point
+	Text string
+}
+
+func NewLabel(text string) *Label {
+	return &Label{Text: text}
+}
+
+func (l *Label) Update() {}
+
+func (l *Label) Size() (w, h int) {
+	return textSize(l.Text)
+}
+
+func (l *Label) Draw(screen *ebiten.Image) {
+	ebitenutil.DebugPrintAt(screen, l.Text, l.x, l.y)
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// List is a column of items with one of them selected. Up and Down move the
+// selection and only hand the focus on past the first and the last item.
+// Rows limits how many items show at once, scrolling to keep the selection
+// in view; 0 shows them all.
+type List struct {
+	point
+	focus
+	Items    []string
+	Selected int
+	Rows     int
+	OnChange func(i int)
+	OnSelect func(i int)
+
+	scroll int
+}
+
+func NewList(items []string, onSelect func(int)) *List {
+	return &List{Items: items, OnSelect: onSelect}
+}
+
+func (l *List) Update() {
+	rows := l.rows()
+	if l.Selected < l.scroll {
+		l.scroll = l.Selected
+	}
+	if l.Selected >= l.scroll+rows {
+		l.scroll = l.Selected - rows + 1
+	}
+}
+
+func (l *List) rows() int {
+	if l.Rows <= 0 || l.Rows > len(l.Items) {
+		return len(l.Items)
+	}
+	return l.Rows
+}
+
+func (l *List) CanFocus() bool {
+	return len(l.Items) > 0
+}
+
+func (l *List) Handle(nav Nav) bool {
+	switch {
+	case nav.Up && l.Selected > 0:
+		l.selected(l.Selected - 1)
+	case nav.Down && l.Selected < len(l.Items)-1:
+		l.selected(l.Selected + 1)
+	case nav.Confirm:
+		if l.OnSelect != nil {
+			l.OnSelect(l.Selected)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (l *List) selected(i int) {
+	l.Selected = i
+	if l.OnChange != nil {
+		l.OnChange(i)
+	}
+}
+
+func (l *List) Size() (w, h int) {
+	for _, item := range l.Items {
+		w = max(w, len(marker)*charW+len([]rune(item))*charW)
+	}
+	return w, l.rows() * lineH
+}
+
+func (l *List) Draw(screen *ebiten.Image) {
+	x := l.x + len(marker)*charW
+	for row := range l.rows() {
+		i := l.scroll + row
+		y := l.y + row*lineH
+		if i == l.Selected {
+			if l.focused {
+				ebitenutil.DebugPrintAt(screen, marker, l.x, y)
+			} else {
+				ebitenutil.DebugPrintAt(screen, "-", l.x, y)
+			}
+		}
+		ebitenutil.DebugPrintAt(screen, l.Items[i], x, y)
+	}
+}
@@ -0,0 +1,68 @@
+package ui
+
+import "github.com/gassyrdaulet/go-fighting-game/base"
+
+// Nav is what the focused widget is asked to do this tick. Pause is left to
+// the screens: it pauses and resumes a match and cancels what a screen is
+// waiting for.
+type Nav struct {
+	Up, Down, Left, Right bool
+	Confirm, Back         bool
+	Pause                 bool
+}
+
+// Held directions start repeating after repeatDelay ticks, once every
+// repeatRate ticks.
+const (
+	repeatDelay = 24
+	repeatRate  = 6
+)
+
+// Navigator turns the held inputs of any number of controllers into menu
+// navigation, so a keyboard and every gamepad can drive the same menu. Attack
+// confirms and Block goes back. Keep one Navigator across every menu: it
+// remembers what was already held, so the press that opens a menu doesn't
+// also go through to it.
+type Navigator struct {
+	prev      base.Input
+	prevPause bool
+	held      int
+}
+
+// Update takes whether any pause button is held and the inputs of every
+// controller.
+func (n *Navigator) Update(pause bool, inputs ...base.Input) Nav {
+	var in base.Input
+	for _, i := range inputs {
+		in.Left = in.Left || i.Left
+		in.Right = in.Right || i.Right
+		in.Up = in.Up || i.Up
+		in.Down = in.Down || i.Down
+		in.Attack = in.Attack || i.Attack
+		in.Block = in.Block || i.Block
+	}
+
+	nav := Nav{
+		Up:      in.Up && !n.prev.Up,
+		Down:    in.Down && !n.prev.Down,
+		Left:    in.Left && !n.prev.Left,
+		Right:   in.Right && !n.prev.Right,
+		Confirm: in.Attack && !n.prev.Attack,
+		Back:    in.Block && !n.prev.Block,
+		Pause:   pause && !n.prevPause,
+	}
+
+	direction := in.Up || in.Down || in.Left || in.Right
+	if direction && in.Up == n.prev.Up && in.Down == n.prev.Down && in.Left == n.prev.Left && in.Right == n.prev.Right {
+		n.held++
+	} else {
+		n.held = 0
+	}
+	if n.held >= repeatDelay && (n.held-repeatDelay)%repeatRate == 0 {
+		nav.Up, nav.Down, nav.Left, nav.Right = in.Up, in.Down, in.Left, in.Right
+	}
+
+	n.prev = in
+	n.prevPause = pause
+	return nav
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// Slider picks a whole number between Min and Max with Left and Right.
+// Confirm steps up and wraps around to Min, so a slider also works with
+// nothing but a confirm button. Format shows the value, strconv.Itoa when
+// nil.
+type Slider struct {
+	point
+	focus
+	Text     string
+	Value    int
+	Min, Max int
+	Format   func(value int) string
+	OnChange func(value int)
+}
+
+func NewSlider(text string, value, minValue, maxValue int, onChange func(int)) *Slider {
+	return &Slider{
+		Text:     text,
+		Value:    value,
+		Min:      minValue,
+		Max:      maxValue,
+		OnChange: onChange,
+	}
+}
+
+func (s *Slider) Update() {}
+
+func (s *Slider) CanFocus() bool {
+	return s.Max > s.Min
+}
+
+func (s *Slider) Handle(nav Nav) bool {
+	value := s.Value
+	switch {
+	case nav.Left:
+		value = max(value-1, s.Min)
+	case nav.Right:
+		value = min(value+1, s.Max)
+	case nav.Confirm:
+		value++
+		if value > s.Max {
+			value = s.Min
+		}
+	default:
+		return false
+	}
+	if value != s.Value {
+		s.Value = value
+		if s.OnChange != nil {
+			s.OnChange(value)
+		}
+	}
+	return true
+}
+
+func (s *Slider) text() string {
+	value := strconv.Itoa(s.Value)
+	if s.Format != nil {
+		value = s.Format(s.Value)
+	}
+	left, right := "<", ">"
+	if s.Value <= s.Min {
+		left = " "
+	}
+	if s.Value >= s.Max {
+		right = " "
+	}
+	return s.Text + ": " + left + " " + value + " " + right
+}
+
+func (s *Slider) Size() (w, h int) {
+	return textSize(marker + s.text())
+}
+
+func (s *Slider) Draw(screen *ebiten.Image) {
+	s.drawMarker(screen, s.x, s.y)
+	ebitenutil.DebugPrintAt(screen, s.text(), s.x+len(marker)*charW, s.y)
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// The widgets draw with the debug font, which has a fixed size glyph.
+const (
+	charW = 6
+	lineH = 16
+)
+
+// marker is drawn in front of the focused widget. Every focusable widget
+// leaves room for it so the text doesn't move when the focus does.
+const marker = "> "
+
+func textSize(text string) (w, h int) {
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		w = max(w, utf8.RuneCountInString(line)*charW)
+	}
+	return w, len(lines) * lineH
+}
+
+// point is the position shared by every widget.
+type point struct {
+	x, y int
+}
+
+func (p *point) SetPosition(x, y int) {
+	p.x, p.y = x, y
+}
+
+// focus is the focus state shared by every focusable widget.
+type focus struct {
+	focused bool
+}
+
+func (f *focus) SetFocused(focused bool) {
+	f.focused = focused
+}
+
+func (f *focus) drawMarker(screen *ebiten.Image, x, y int) {
+	if f.focused {
+		ebitenutil.DebugPrintAt(screen, marker, x, y)
+	}
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// Toggle is an on/off switch. Confirm, Left and Right all flip it.
+type Toggle struct {
+	point
+	focus
+	Text     string
+	Value    bool
+	OnChange func(value bool)
+}
+
+func NewToggle(text string, value bool, onChange func(bool)) *Toggle {
+	return &Toggle{Text: text, Value: value, OnChange: onChange}
+}
+
+func (t *Toggle) Update() {}
+
+func (t *Toggle) CanFocus() bool {
+	return true
+}
+
+func (t *Toggle) Handle(nav Nav) bool {
+	if !nav.Confirm && !nav.Left && !nav.Right {
+		return false
+	}
+	t.Value = !t.Value
+	if t.OnChange != nil {
+		t.OnChange(t.Value)
+	}
+	return true
+}
+
+func (t *Toggle) text() string {
+	if t.Value {
+		return t.Text + ": on"
+	}
+	return t.Text + ": off"
+}
+
+func (t *Toggle) Size() (w, h int) {
+	return textSize(marker + t.text())
+}
+
+func (t *Toggle) Draw(screen *ebiten.Image) {
+	t.drawMarker(screen, t.x, t.y)
+	ebitenutil.DebugPrintAt(screen, t.text(), t.x+len(marker)*charW, t.y)
+}
@@ -15,6 +15,7 @@ type Character struct {
 	JumpForce            float64
 	Weight        		 float64
 	Width, Height        float64
+	CrouchHeight         float64
 	Damage               int
 	MaxHP                int
     AttackTicks          int
@@ -23,10 +24,53 @@ type Character struct {
 	DyingTicks       	 int
 	HurtingTicks       	 int
 	AttackRange       	 float64
+	// Guard is drained by the damage of blocked hits and refills by
+	// GuardRegen every tick the character isn't blocking. Blocked hits deal
+	// BlockDamage and push back by BlockKnockback of their usual amount. A
+	// hit in the first ParryTicks of a block is parried and stuns the
+	// attacker for ParryStunTicks; an emptied guard stuns the blocker for
+	// GuardBreakTicks.
+	Guard                float64
+	GuardRegen           float64
+	BlockDamage          float64
+	BlockKnockback       float64
+	ParryTicks           int
+	ParryStunTicks       int
+	GuardBreakTicks      int
+	Combo                []Hit
+	Projectiles          map[string]*Projectile
+	FrameData            map[string]*FrameData
 	Animations           map[string]*base.Animation
 	AnimationsConfigs    []base.AnimationConfig
 }
 
+// Hit is one attack of a ground combo. Its damage lands when Ticks run out;
+// pressing Attack again during the last ChainTicks of it queues the next hit
+// of the combo, which starts as soon as this one has landed.
+//
+// KnockbackX pushes the victim away from the attacker and KnockbackY launches
+// it, negative being up. Both are divided by the victim's weight and grow by
+// KnockbackGrowth for every point of damage it has already taken. Hitstun is
+// how many ticks the victim stays hurt, the victim's own HurtingTicks when 0.
+//
+// A hit with a Projectile throws it once, as soon as the hit becomes active.
+// Ranged hits are the ones projectiles land; parrying them doesn't stun the
+// thrower.
+type Hit struct {
+	Animation       string
+	RunAnimation    string
+	Damage          int
+	Range           float64
+	Ticks           int
+	ChainTicks      int
+	KnockbackX      float64
+	KnockbackY      float64
+	KnockbackGrowth float64
+	Hitstun         int
+	Projectile      string
+	Ranged          bool
+}
+
 type CharactersJSON struct {
     Characters []CharacterJSON `json:"characters"`
 }
@@ -41,15 +85,39 @@ type CharacterJSON struct {
     Weight              float64          `json:"weight"`
     Width               float64          `json:"width"`
     Height              float64          `json:"height"`
+    CrouchHeight        float64          `json:"crouchHeight"`
     AttackTicks         int              `json:"attackTicks"`
     AttackCooldownTicks int              `json:"attackCooldownTicks"`
     ChargingJumpTicks   int              `json:"chargingJumpTicks"`
 	DyingTicks       	int              `json:"dyingTicks"`
     HurtingTicks        int              `json:"hurtingTicks"`
 	AttackRange       	float64          `json:"attackRange"`
+    Guard               float64          `json:"guard"`
+    GuardRegen          float64          `json:"guardRegen"`
+    BlockDamage         float64          `json:"blockDamage"`
+    BlockKnockback      float64          `json:"blockKnockback"`
+    ParryTicks          int              `json:"parryTicks"`
+    ParryStunTicks      int              `json:"parryStunTicks"`
+    GuardBreakTicks     int              `json:"guardBreakTicks"`
+    Combo               []HitJSON        `json:"combo"`
+    Projectiles         []ProjectileJSON `json:"projectiles"`
     Animations          []AnimationJSON  `json:"animations"`
 }
 
+type HitJSON struct {
+    Animation       string  `json:"animation"`
+    RunAnimation    string  `json:"runAnimation"`
+    Damage          int     `json:"damage"`
+    Range           float64 `json:"range"`
+    Ticks           int     `json:"ticks"`
+    ChainTicks      int     `json:"chainTicks"`
+    KnockbackX      float64 `json:"knockbackX"`
+    KnockbackY      float64 `json:"knockbackY"`
+    KnockbackGrowth float64 `json:"knockbackGrowth"`
+    Hitstun         int     `json:"hitstun"`
+    Projectile      string  `json:"projectile"`
+}
+
 type AnimationJSON struct {
     Name        string 	   `json:"name"`
     Group       string    `json:"group"`
@@ -63,6 +131,11 @@ type AnimationJSON struct {
     OffsetX     float64    `json:"offsetX"`
     OffsetY     float64    `json:"offsetY"`
     Loop        bool       `json:"loop"`
+    Startup     int        `json:"startup"`
+    Active      int        `json:"active"`
+    Recovery    int        `json:"recovery"`
+    Hitboxes    [][]BoxJSON `json:"hitboxes"`
+    Hurtboxes   [][]BoxJSON `json:"hurtboxes"`
 }
 
 func LoadCharacters(path string) (map[string]*Character, error) {
@@ -89,17 +162,28 @@ func LoadCharacters(path string) (map[string]*Character, error) {
             Weight:              c.Weight,
             Width:               c.Width,
             Height:              c.Height,
+            CrouchHeight:        c.CrouchHeight,
             AttackTicks:         c.AttackTicks,
             AttackCooldownTicks: c.AttackCooldownTicks,
             ChargingJumpTicks:   c.ChargingJumpTicks,
             DyingTicks:          c.DyingTicks,
             HurtingTicks:        c.HurtingTicks,
             AttackRange:         c.AttackRange,
+            Guard:               c.Guard,
+            GuardRegen:          c.GuardRegen,
+            BlockDamage:         c.BlockDamage,
+            BlockKnockback:      c.BlockKnockback,
+            ParryTicks:          c.ParryTicks,
+            ParryStunTicks:      c.ParryStunTicks,
+            GuardBreakTicks:     c.GuardBreakTicks,
+            FrameData:           make(map[string]*FrameData),
             Animations:          make(map[string]*base.Animation),
             AnimationsConfigs:   []base.AnimationConfig{},
         }
 
+        animations := make(map[string]bool, len(c.Animations))
         for _, a := range c.Animations {
+            animations[a.Name] = true
             cfg := base.Anim(
                 a.Name,
                 a.Image,
@@ -116,13 +200,56 @@ func LoadCharacters(path string) (map[string]*Character, error) {
             )
 
             char.AnimationsConfigs = append(char.AnimationsConfigs, cfg)
+
+            fd, err := frameData(a)
+            if err != nil {
+                return nil, fmt.Errorf("character %s: %w", c.ID, err)
+            }
+            if fd != nil {
+                char.FrameData[a.Name] = fd
+            }
         }
 
-		animations, err := base.LoadAnimations(char.AnimationsConfigs)
+		projectiles, err := projectiles(c.Projectiles, animations)
 		if err != nil {
-			return nil, fmt.Errorf("character %s: %w", char.ID, err)
+			return nil, fmt.Errorf("character %s: %w", c.ID, err)
+		}
+		char.Projectiles = projectiles
+
+		for i, h := range c.Combo {
+			if h.Projectile != "" && projectiles[h.Projectile] == nil {
+				return nil, fmt.Errorf("character %s: hit %d: unknown projectile %s", c.ID, i+1, h.Projectile)
+			}
+
+			char.Combo = append(char.Combo, Hit{
+				Animation:       h.Animation,
+				RunAnimation:    h.RunAnimation,
+				Damage:          h.Damage,
+				Range:           h.Range,
+				Ticks:           h.Ticks,
+				ChainTicks:      h.ChainTicks,
+				KnockbackX:      h.KnockbackX,
+				KnockbackY:      h.KnockbackY,
+				KnockbackGrowth: h.KnockbackGrowth,
+				Hitstun:         h.Hitstun,
+				Projectile:      h.Projectile,
+			})
+		}
+		if len(char.Combo) == 0 {
+			char.Combo = []Hit{{
+				Animation:    "attack",
+				RunAnimation: "run_attack",
+				Damage:       char.Damage,
+				Range:        char.AttackRange,
+				Ticks:        char.AttackTicks,
+			}}
 		}
-		char.Animations = animations
+
+		if char.CrouchHeight <= 0 {
+			char.CrouchHeight = char.Height
+		}
+
+		char.Animations = base.NewAnimations(char.AnimationsConfigs)
 
         result[char.ID] = char
     }
@@ -0,0 +1,87 @@
+package characters
+
+import "fmt"
+
+// Box is a rectangle relative to the actor facing right: X from the center of
+// its body, Y from the top of it. Actors facing left mirror it.
+type Box struct {
+	X, Y, W, H float64
+}
+
+// FrameData tells which frames of an animation can hit and where the actor
+// can be hit on each frame. Startup, Active and Recovery are counted in
+// animation frames and add up to the whole animation. Hitboxes[i] and
+// Hurtboxes[i] belong to frame i; a frame without hurtboxes falls back to
+// the actor's body.
+type FrameData struct {
+	Startup   int
+	Active    int
+	Recovery  int
+	Hitboxes  [][]Box
+	Hurtboxes [][]Box
+}
+
+func (f *FrameData) IsActive(frame int) bool {
+	return frame >= f.Startup && frame < f.Startup+f.Active
+}
+
+func (f *FrameData) HitboxesAt(frame int) []Box {
+	if !f.IsActive(frame) || frame >= len(f.Hitboxes) {
+		return nil
+	}
+	return f.Hitboxes[frame]
+}
+
+func (f *FrameData) HurtboxesAt(frame int) []Box {
+	if frame < 0 || frame >= len(f.Hurtboxes) {
+		return nil
+	}
+	return f.Hurtboxes[frame]
+}
+
+type BoxJSON struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+// frameData builds the frame data of an animation entry, nil when the entry
+// has none.
+func frameData(a AnimationJSON) (*FrameData, error) {
+	if a.Startup == 0 && a.Active == 0 && a.Recovery == 0 && a.Hitboxes == nil && a.Hurtboxes == nil {
+		return nil, nil
+	}
+
+	if a.Startup+a.Active+a.Recovery != a.Frames && (a.Active > 0 || a.Hitboxes != nil) {
+		return nil, fmt.Errorf("animation %s: %d startup, %d active and %d recovery frames for %d frames",
+			a.Name, a.Startup, a.Active, a.Recovery, a.Frames)
+	}
+	if a.Hitboxes != nil && len(a.Hitboxes) != a.Frames {
+		return nil, fmt.Errorf("animation %s: hitboxes for %d frames, expected %d", a.Name, len(a.Hitboxes), a.Frames)
+	}
+	if a.Hurtboxes != nil && len(a.Hurtboxes) != a.Frames {
+		return nil, fmt.Errorf("animation %s: hurtboxes for %d frames, expected %d", a.Name, len(a.Hurtboxes), a.Frames)
+	}
+
+	return &FrameData{
+		Startup:   a.Startup,
+		Active:    a.Active,
+		Recovery:  a.Recovery,
+		Hitboxes:  boxes(a.Hitboxes),
+		Hurtboxes: boxes(a.Hurtboxes),
+	}, nil
+}
+
+func boxes(frames [][]BoxJSON) [][]Box {
+	if frames == nil {
+		return nil
+	}
+	result := make([][]Box, len(frames))
+	for i, frame := range frames {
+		for _, b := range frame {
+			result[i] = append(result[i], Box{X: b.X, Y: b.Y, W: b.W, H: b.H})
+		}
+	}
+	return result
+}
@@ -0,0 +1,76 @@
+package characters
+
+import "fmt"
+
+// Projectile is something a character throws or fires. It leaves OffsetX in
+// front of the center of the thrower and OffsetY below the top of it, flies
+// at Speed, with SpeedY straight up or down, and falls with GravityScale of
+// the usual gravity. It is gone after Lifetime ticks, on the first Solid tile
+// it runs into or on the first actor it hits with Hit.
+type Projectile struct {
+	Name             string
+	Animation        string
+	Width, Height    float64
+	OffsetX, OffsetY float64
+	Speed            float64
+	SpeedY           float64
+	GravityScale     float64
+	Lifetime         int
+	Hit              Hit
+}
+
+type ProjectileJSON struct {
+	Name            string  `json:"name"`
+	Animation       string  `json:"animation"`
+	Width           float64 `json:"width"`
+	Height          float64 `json:"height"`
+	OffsetX         float64 `json:"offsetX"`
+	OffsetY         float64 `json:"offsetY"`
+	Speed           float64 `json:"speed"`
+	SpeedY          float64 `json:"speedY"`
+	GravityScale    float64 `json:"gravityScale"`
+	Lifetime        int     `json:"lifetime"`
+	Damage          int     `json:"damage"`
+	KnockbackX      float64 `json:"knockbackX"`
+	KnockbackY      float64 `json:"knockbackY"`
+	KnockbackGrowth float64 `json:"knockbackGrowth"`
+	Hitstun         int     `json:"hitstun"`
+}
+
+// projectiles builds the projectiles of a character, keyed by name.
+func projectiles(list []ProjectileJSON, animations map[string]bool) (map[string]*Projectile, error) {
+	result := make(map[string]*Projectile, len(list))
+	for _, p := range list {
+		if _, ok := result[p.Name]; ok {
+			return nil, fmt.Errorf("projectile %s: defined twice", p.Name)
+		}
+		if !animations[p.Animation] {
+			return nil, fmt.Errorf("projectile %s: unknown animation %s", p.Name, p.Animation)
+		}
+		if p.Lifetime <= 0 {
+			return nil, fmt.Errorf("projectile %s: lifetime must be positive", p.Name)
+		}
+
+		result[p.Name] = &Projectile{
+			Name:         p.Name,
+			Animation:    p.Animation,
+			Width:        p.Width,
+			Height:       p.Height,
+			OffsetX:      p.OffsetX,
+			OffsetY:      p.OffsetY,
+			Speed:        p.Speed,
+			SpeedY:       p.SpeedY,
+			GravityScale: p.GravityScale,
+			Lifetime:     p.Lifetime,
+			Hit: Hit{
+				Damage:          p.Damage,
+				KnockbackX:      p.KnockbackX,
+				KnockbackY:      p.KnockbackY,
+				KnockbackGrowth: p.KnockbackGrowth,
+				Hitstun:         p.Hitstun,
+				Ranged:          true,
+			},
+		}
+	}
+	return result, nil
+}
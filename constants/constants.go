@@ -8,10 +8,16 @@ const (
 	CameraMaxOffsetX   = 100
 	CameraMaxOffsetY   = 100
 	Gravity            = 0.5
+	TicksPerSecond     = 60
 	TileSize           = 32
 	LevelsDirectory    = "levels/"
+	DefaultLevel       = "ai-arena"
 	TilesetDirectory   = "levels/tileset/"
+	ReplaysDirectory   = "replays/"
+	LastReplayName     = "last.json"
 	CameraAnchorWeight = 0.8
 	CameraSmoothness   = 0.22
 	VirtualBorders     = false
+	GamepadDeadzone    = 0.25
+	ControlsFile       = "controls.json"
 )
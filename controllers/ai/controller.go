@@ -0,0 +1,262 @@
+package ai
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/base/navigation"
+	"github.com/gassyrdaulet/go-fighting-game/base/physics"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+	"github.com/gassyrdaulet/go-fighting-game/entities/actor"
+)
+
+const (
+	aiMaxRetreatHPRatio = 0.6
+	aiMinAttackChance   = 0.3
+	aiRetreatDistance   = 120
+	aiJumpHeightTiles   = 1
+	aiVerticalTolerance = 0.8
+	aiReplanTicks       = 15
+	aiWaypointTolerance = 6
+)
+
+// Controller plays an actor on its own. It has to be attached to the actor
+// it drives once the players are spawned, after that it reads the world every
+// tick and answers with the same Input a keyboard would produce.
+type Controller struct {
+	Self    *actor.Actor
+	Players []*actor.Actor
+	World   *physics.World
+	Nav     *navigation.Graph
+	Profile *Profile
+
+	path        []navigation.Waypoint
+	replanTicks int
+	pending     []base.Input
+	rng         *rand.Rand
+}
+
+func NewController(profile *Profile, seed uint64) *Controller {
+	if profile == nil {
+		profile = DefaultProfile
+	}
+	return &Controller{
+		Profile: profile,
+		pending: make([]base.Input, profile.ReactionTicks),
+		rng:     rand.New(rand.NewPCG(seed, seed)),
+	}
+}
+
+func (c *Controller) Attach(self *actor.Actor, players []*actor.Actor, world *physics.World) {
+	c.Self = self
+	c.Players = players
+	c.World = world
+}
+
+// UseNavigation lets the bot plan routes across platforms instead of only
+// running and jumping straight at its target.
+func (c *Controller) UseNavigation(nav *navigation.Graph) {
+	c.Nav = nav
+	c.path = nil
+	c.replanTicks = 0
+}
+
+// GetInput hands out what the bot decided ReactionTicks ago, after a chance of
+// fumbling it and of throwing in a jump.
+func (c *Controller) GetInput() base.Input {
+	input := c.decide()
+
+	if c.rng.Float64() < c.Profile.JumpRate {
+		input.Up = true
+	}
+	if c.rng.Float64() < c.Profile.MistakeRate {
+		c.fumble(&input)
+	}
+
+	if len(c.pending) == 0 {
+		return input
+	}
+	delayed := c.pending[0]
+	c.pending = append(c.pending[1:], input)
+	return delayed
+}
+
+func (c *Controller) fumble(input *base.Input) {
+	switch c.rng.IntN(3) {
+	case 0:
+		input.Left, input.Right = input.Right, input.Left
+	case 1:
+		input.Attack = !input.Attack
+	default:
+		input.Up = !input.Up
+	}
+}
+
+func (c *Controller) decide() base.Input {
+	var input base.Input
+
+	if c.Self == nil || c.World == nil || c.Self.Dead || c.Self.Dying {
+		return input
+	}
+
+	target := c.nearestOpponent()
+	if target == nil {
+		return input
+	}
+
+	dx := target.X - c.Self.X
+	dy := target.Y - c.Self.Y
+	dir := 1
+	if dx < 0 {
+		dir = -1
+	}
+
+	if c.lowHP() && math.Abs(dx) < aiRetreatDistance {
+		c.move(&input, -dir)
+		if c.blocked(-dir) || !c.groundAhead(-dir) {
+			input.Up = true
+		}
+		return input
+	}
+
+	if c.inRange(target, dx, dy) {
+		if c.Self.Direction != dir {
+			c.move(&input, dir)
+		} else if c.rng.Float64() < aiMinAttackChance+(1-aiMinAttackChance)*c.Profile.Aggression {
+			input.Attack = true
+		}
+		return input
+	}
+
+	if c.Nav != nil && c.followPath(&input, target) {
+		return input
+	}
+
+	c.move(&input, dir)
+
+	if dy < -aiJumpHeightTiles*constants.TileSize {
+		input.Up = true
+	}
+	if c.blocked(dir) {
+		input.Up = true
+	}
+	if !c.groundAhead(dir) && dy <= 0 {
+		input.Up = true
+	}
+
+	return input
+}
+
+func (c *Controller) followPath(input *base.Input, target *actor.Actor) bool {
+	a := c.Self
+
+	if c.replanTicks <= 0 || len(c.path) == 0 {
+		c.path, _ = c.Nav.FindPath(a.X, a.Y+a.Height, target.X, target.Y+target.Height)
+		c.replanTicks = aiReplanTicks
+	}
+	c.replanTicks--
+
+	if a.OnGround {
+		if n, ok := c.Nav.NodeAt(a.X, a.Y+a.Height); ok {
+			for i, w := range c.path {
+				if w.NodeID == n.ID {
+					c.path = c.path[i:]
+					break
+				}
+			}
+		}
+	}
+
+	if len(c.path) < 2 {
+		return false
+	}
+
+	from, next := c.path[0], c.path[1]
+
+	if next.Kind == navigation.Jump && a.OnGround {
+		if math.Abs(from.X-a.X) > aiWaypointTolerance {
+			c.move(input, sign(from.X-a.X))
+			return true
+		}
+		input.Up = true
+	}
+
+	if math.Abs(next.X-a.X) > aiWaypointTolerance || !a.OnGround {
+		c.move(input, sign(next.X-a.X))
+	}
+
+	return true
+}
+
+func (c *Controller) nearestOpponent() *actor.Actor {
+	var nearest *actor.Actor
+	best := math.MaxFloat64
+
+	for _, o := range c.Players {
+		if o == c.Self || o.Dead || o.Dying || c.Self.Ally(o) {
+			continue
+		}
+		d := math.Hypot(o.X-c.Self.X, o.Y-c.Self.Y)
+		if d < best {
+			best = d
+			nearest = o
+		}
+	}
+
+	return nearest
+}
+
+func (c *Controller) lowHP() bool {
+	ratio := aiMaxRetreatHPRatio * (1 - c.Profile.Aggression)
+	return float64(c.Self.Hp) <= float64(c.Self.MaxHp)*ratio
+}
+
+func (c *Controller) inRange(target *actor.Actor, dx, dy float64) bool {
+	reach := c.Self.Width/2 + c.Self.AttackRange + target.Width/2
+	return math.Abs(dx) <= reach && math.Abs(dy) <= c.Self.Height*aiVerticalTolerance
+}
+
+func (c *Controller) move(input *base.Input, dir int) {
+	if dir < 0 {
+		input.Left = true
+	} else {
+		input.Right = true
+	}
+}
+
+// blocked reports whether a solid tile stands right in front of the actor.
+func (c *Controller) blocked(dir int) bool {
+	a := c.Self
+	tileX := int((a.X + float64(dir)*(a.Width/2+a.Speed)) / constants.TileSize)
+	tileY1 := int(a.Y / constants.TileSize)
+	tileY2 := int((a.Y + a.Height - 1) / constants.TileSize)
+
+	for ty := tileY1; ty <= tileY2; ty++ {
+		if c.World.Tiles.IsSolid(tileX, ty) {
+			return true
+		}
+	}
+	return false
+}
+
+// groundAhead reports whether there is something to stand on one step ahead.
+func (c *Controller) groundAhead(dir int) bool {
+	a := c.Self
+	if !a.OnGround {
+		return true
+	}
+	tileX := int((a.X + float64(dir)*(a.Width/2+a.Speed)) / constants.TileSize)
+	tileY := int((a.Y + a.Height) / constants.TileSize)
+
+	// At the bottom of a slope the floor ahead starts a row above the feet.
+	return c.World.Tiles.IsSolid(tileX, tileY) || c.World.Tiles.IsPlatform(tileX, tileY) ||
+		c.World.Tiles.IsSlope(tileX, tileY) || c.World.Tiles.IsSlope(tileX, tileY-1)
+}
+
+func sign(v float64) int {
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,68 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Profile tunes how well an AIController plays.
+type Profile struct {
+	ID            string
+	Name          string
+	ReactionTicks int
+	Aggression    float64
+	MistakeRate   float64
+	JumpRate      float64
+}
+
+var DefaultProfile = &Profile{
+	ID:         "default",
+	Name:       "Default",
+	Aggression: 0.5,
+}
+
+type ProfilesJSON struct {
+	Profiles []ProfileJSON `json:"profiles"`
+}
+
+type ProfileJSON struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	ReactionTicks int     `json:"reactionTicks"`
+	Aggression    float64 `json:"aggression"`
+	MistakeRate   float64 `json:"mistakeRate"`
+	JumpRate      float64 `json:"jumpRate"`
+}
+
+// LoadProfiles keeps the order of the file, so menus can list the profiles
+// from the weakest to the strongest.
+func LoadProfiles(path string) ([]*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonData ProfilesJSON
+	if err := json.Unmarshal(data, &jsonData); err != nil {
+		return nil, err
+	}
+
+	result := make([]*Profile, 0, len(jsonData.Profiles))
+
+	for _, p := range jsonData.Profiles {
+		if p.ReactionTicks < 0 {
+			return nil, fmt.Errorf("ai profile %s: negative reactionTicks", p.ID)
+		}
+		result = append(result, &Profile{
+			ID:            p.ID,
+			Name:          p.Name,
+			ReactionTicks: p.ReactionTicks,
+			Aggression:    p.Aggression,
+			MistakeRate:   p.MistakeRate,
+			JumpRate:      p.JumpRate,
+		})
+	}
+
+	return result, nil
+}
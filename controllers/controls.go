@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Action is one of the inputs a player can bind.
+type Action string
+
+const (
+	ActionLeft   Action = "left"
+	ActionRight  Action = "right"
+	ActionUp     Action = "up"
+	ActionDown   Action = "down"
+	ActionAttack Action = "attack"
+	ActionBlock  Action = "block"
+)
+
+// Actions lists every bindable action in the order the controls screen shows
+// them.
+var Actions = []Action{ActionLeft, ActionRight, ActionUp, ActionDown, ActionAttack, ActionBlock}
+
+// Controls is what the controls file holds: the keys and gamepad buttons of
+// every player slot. Keys are stored by their Ebiten names ("ArrowLeft",
+// "Space", "A" and so on), buttons by their standard layout names
+// ("RightBottom", "LeftLeft" and so on).
+type Controls struct {
+	Players []SlotControls `json:"players"`
+}
+
+type SlotControls struct {
+	Keys    map[Action]ebiten.Key    `json:"keys"`
+	Buttons map[Action]GamepadButton `json:"buttons"`
+}
+
+// DefaultControls is used when there is no controls file yet: arrows, Space
+// and right Shift for the first player, WASD, F and G for the second, the
+// D-pad and the bottom and right face buttons on gamepads.
+func DefaultControls() *Controls {
+	buttons := func() map[Action]GamepadButton {
+		return map[Action]GamepadButton{
+			ActionLeft:   GamepadButton(ebiten.StandardGamepadButtonLeftLeft),
+			ActionRight:  GamepadButton(ebiten.StandardGamepadButtonLeftRight),
+			ActionUp:     GamepadButton(ebiten.StandardGamepadButtonLeftTop),
+			ActionDown:   GamepadButton(ebiten.StandardGamepadButtonLeftBottom),
+			ActionAttack: GamepadButton(ebiten.StandardGamepadButtonRightBottom),
+			ActionBlock:  GamepadButton(ebiten.StandardGamepadButtonRightRight),
+		}
+	}
+
+	return &Controls{
+		Players: []SlotControls{
+			{
+				Keys: map[Action]ebiten.Key{
+					ActionLeft:   ebiten.KeyLeft,
+					ActionRight:  ebiten.KeyRight,
+					ActionUp:     ebiten.KeyUp,
+					ActionDown:   ebiten.KeyDown,
+					ActionAttack: ebiten.KeySpace,
+					ActionBlock:  ebiten.KeyShiftRight,
+				},
+				Buttons: buttons(),
+			},
+			{
+				Keys: map[Action]ebiten.Key{
+					ActionLeft:   ebiten.KeyA,
+					ActionRight:  ebiten.KeyD,
+					ActionUp:     ebiten.KeyW,
+					ActionDown:   ebiten.KeyS,
+					ActionAttack: ebiten.KeyF,
+					ActionBlock:  ebiten.KeyG,
+				},
+				Buttons: buttons(),
+			},
+		},
+	}
+}
+
+// LoadControls reads the controls file. A missing file is not an error, the
+// defaults are returned instead and the file gets written on the first
+// rebind. Actions the file leaves out keep their default binding.
+func LoadControls(path string) (*Controls, error) {
+	controls := DefaultControls()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return controls, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file Controls
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("controls %s: %w", path, err)
+	}
+
+	for i, slot := range file.Players {
+		if i >= len(controls.Players) {
+			break
+		}
+		for action, key := range slot.Keys {
+			if !validAction(action) {
+				return nil, fmt.Errorf("controls %s: unknown action %q", path, action)
+			}
+			controls.Players[i].Keys[action] = key
+		}
+		for action, button := range slot.Buttons {
+			if !validAction(action) {
+				return nil, fmt.Errorf("controls %s: unknown action %q", path, action)
+			}
+			controls.Players[i].Buttons[action] = button
+		}
+	}
+
+	return controls, nil
+}
+
+func SaveControls(path string, c *Controls) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func validAction(action Action) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Conflict is two actions bound to the same key or button. Keys are shared by
+// every slot on the keyboard, so a key conflict can be between two players;
+// every slot has its own gamepad, so button conflicts stay within one slot.
+type Conflict struct {
+	Slot, OtherSlot     int
+	Action, OtherAction Action
+	Gamepad             bool
+}
+
+func (c Conflict) String() string {
+	device := "key"
+	if c.Gamepad {
+		device = "button"
+	}
+	return fmt.Sprintf("P%d %s and P%d %s share a %s", c.Slot+1, c.Action, c.OtherSlot+1, c.OtherAction, device)
+}
+
+// KeyOwner reports which slot and action the key is bound to, skipping the
+// binding at (slot, action) itself.
+func (c *Controls) KeyOwner(key ebiten.Key, slot int, action Action) (int, Action, bool) {
+	for i, p := range c.Players {
+		for _, a := range Actions {
+			if (i != slot || a != action) && p.Keys[a] == key {
+				return i, a, true
+			}
+		}
+	}
+	return 0, "", false
+}
+
+// ButtonOwner reports which action of the slot the button is bound to,
+// skipping action itself.
+func (c *Controls) ButtonOwner(button GamepadButton, slot int, action Action) (Action, bool) {
+	for _, a := range Actions {
+		if a != action && c.Players[slot].Buttons[a] == button {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+// Conflicts lists every pair of actions sharing a binding, each pair once.
+func (c *Controls) Conflicts() []Conflict {
+	var conflicts []Conflict
+	for i, p := range c.Players {
+		for x, a := range Actions {
+			for j := i; j < len(c.Players); j++ {
+				for y, b := range Actions {
+					if j == i && y <= x {
+						continue
+					}
+					if p.Keys[a] == c.Players[j].Keys[b] {
+						conflicts = append(conflicts, Conflict{Slot: i, Action: a, OtherSlot: j, OtherAction: b})
+					}
+					if j == i && p.Buttons[a] == p.Buttons[b] {
+						conflicts = append(conflicts, Conflict{Slot: i, Action: a, OtherSlot: i, OtherAction: b, Gamepad: true})
+					}
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+// GamepadButton is a standard layout button that reads and writes itself by
+// name in the controls file.
+type GamepadButton ebiten.StandardGamepadButton
+
+var buttonNames = map[ebiten.StandardGamepadButton]string{
+	ebiten.StandardGamepadButtonRightBottom:      "RightBottom",
+	ebiten.StandardGamepadButtonRightRight:       "RightRight",
+	ebiten.StandardGamepadButtonRightLeft:        "RightLeft",
+	ebiten.StandardGamepadButtonRightTop:         "RightTop",
+	ebiten.StandardGamepadButtonFrontTopLeft:     "FrontTopLeft",
+	ebiten.StandardGamepadButtonFrontTopRight:    "FrontTopRight",
+	ebiten.StandardGamepadButtonFrontBottomLeft:  "FrontBottomLeft",
+	ebiten.StandardGamepadButtonFrontBottomRight: "FrontBottomRight",
+	ebiten.StandardGamepadButtonCenterLeft:       "CenterLeft",
+	ebiten.StandardGamepadButtonCenterRight:      "CenterRight",
+	ebiten.StandardGamepadButtonLeftStick:        "LeftStick",
+	ebiten.StandardGamepadButtonRightStick:       "RightStick",
+	ebiten.StandardGamepadButtonLeftTop:          "LeftTop",
+	ebiten.StandardGamepadButtonLeftBottom:       "LeftBottom",
+	ebiten.StandardGamepadButtonLeftLeft:         "LeftLeft",
+	ebiten.StandardGamepadButtonLeftRight:        "LeftRight",
+	ebiten.StandardGamepadButtonCenterCenter:     "CenterCenter",
+}
+
+func (b GamepadButton) String() string {
+	if name, ok := buttonNames[ebiten.StandardGamepadButton(b)]; ok {
+		return name
+	}
+	return fmt.Sprintf("Button%d", int(b))
+}
+
+func (b GamepadButton) MarshalText() ([]byte, error) {
+	if _, ok := buttonNames[ebiten.StandardGamepadButton(b)]; !ok {
+		return nil, fmt.Errorf("controllers: unknown gamepad button %d", int(b))
+	}
+	return []byte(b.String()), nil
+}
+
+func (b *GamepadButton) UnmarshalText(text []byte) error {
+	for button, name := range buttonNames {
+		if name == string(text) {
+			*b = GamepadButton(button)
+			return nil
+		}
+	}
+	return fmt.Errorf("controllers: unknown gamepad button %q", text)
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// GamepadController reads a gamepad through Ebiten's standard layout, so the
+// same buttons work whatever pad is plugged in. Directions come from the
+// bound buttons (the D-pad by default) or the left stick once it leaves the
+// deadzone.
+type GamepadController struct {
+	ID       ebiten.GamepadID
+	Deadzone float64
+
+	Left, Right, Up, Down, Attack, Block ebiten.StandardGamepadButton
+
+	prevAttack bool
+}
+
+func NewGamepadController(id ebiten.GamepadID, deadzone float64) *GamepadController {
+	return &GamepadController{
+		ID:       id,
+		Deadzone: deadzone,
+		Left:     ebiten.StandardGamepadButtonLeftLeft,
+		Right:    ebiten.StandardGamepadButtonLeftRight,
+		Up:       ebiten.StandardGamepadButtonLeftTop,
+		Down:     ebiten.StandardGamepadButtonLeftBottom,
+		Attack:   ebiten.StandardGamepadButtonRightBottom,
+		Block:    ebiten.StandardGamepadButtonRightRight,
+	}
+}
+
+func (c *GamepadController) GetInput() base.Input {
+	var input base.Input
+
+	if !ebiten.IsStandardGamepadLayoutAvailable(c.ID) {
+		return input
+	}
+
+	h := ebiten.StandardGamepadAxisValue(c.ID, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	v := ebiten.StandardGamepadAxisValue(c.ID, ebiten.StandardGamepadAxisLeftStickVertical)
+
+	if c.pressed(c.Left) || h < -c.Deadzone {
+		input.Left = true
+	}
+	if c.pressed(c.Right) || h > c.Deadzone {
+		input.Right = true
+	}
+	if c.pressed(c.Up) || v < -c.Deadzone {
+		input.Up = true
+	}
+	if c.pressed(c.Down) || v > c.Deadzone {
+		input.Down = true
+	}
+
+	if c.pressed(c.Block) {
+		input.Block = true
+	}
+
+	attackPressed := c.pressed(c.Attack)
+	if attackPressed && !c.prevAttack {
+		input.Attack = true
+	}
+	c.prevAttack = attackPressed
+
+	return input
+}
+
+func (c *GamepadController) pressed(button ebiten.StandardGamepadButton) bool {
+	return ebiten.IsStandardGamepadButtonPressed(c.ID, button)
+}
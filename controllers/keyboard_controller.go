@@ -7,14 +7,14 @@ import (
 
 type KeyboardController struct {
 	// клавиши
-	Left, Right, Up, Down, Attack ebiten.Key
+	Left, Right, Up, Down, Attack, Block ebiten.Key
 
 	prevKeys map[ebiten.Key]bool
 }
 
-func NewKeyboardController(left, right, up, down, attack ebiten.Key) *KeyboardController {
+func NewKeyboardController(left, right, up, down, attack, block ebiten.Key) *KeyboardController {
 	return &KeyboardController{
-		Left: left, Right: right, Up: up, Down: down, Attack: attack,
+		Left: left, Right: right, Up: up, Down: down, Attack: attack, Block: block,
 		prevKeys: make(map[ebiten.Key]bool),
 	}
 }
@@ -34,6 +34,9 @@ func (c *KeyboardController) GetInput() base.Input {
 	if ebiten.IsKeyPressed(c.Down) {
 		input.Down = true
 	}
+	if ebiten.IsKeyPressed(c.Block) {
+		input.Block = true
+	}
 
 	attackPressed := ebiten.IsKeyPressed(c.Attack)
 	if attackPressed && !c.prevKeys[c.Attack] {
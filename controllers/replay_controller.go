@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/replay"
+)
+
+// ReplayController plays back one player's recorded inputs, one tick per
+// GetInput call. Once the recording runs out it returns empty inputs.
+type ReplayController struct {
+	replay *replay.Replay
+	player int
+	tick   int
+}
+
+func NewReplayController(r *replay.Replay, player int) *ReplayController {
+	return &ReplayController{
+		replay: r,
+		player: player,
+	}
+}
+
+func (c *ReplayController) GetInput() base.Input {
+	input := c.replay.Input(c.player, c.tick)
+	c.tick++
+	return input
+}
+
+func (c *ReplayController) Done() bool {
+	return c.tick >= c.replay.Ticks()
+}
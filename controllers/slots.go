@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"slices"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Slots decides which device plays each player slot. Every slot has a
+// keyboard layout to fall back on; a gamepad plugged in takes over the first
+// slot no other gamepad holds and hands it back when it is unplugged. Both
+// read the slot's bindings from Controls.
+type Slots struct {
+	Deadzone float64
+
+	controls  *Controls
+	keyboards []*KeyboardController
+	pads      []*GamepadController
+	connected []ebiten.GamepadID
+	// inputs are read once a tick, in Update. The controllers only report
+	// an attack on the tick it is pressed, so reading them twice in a tick
+	// would lose it for whoever reads second.
+	inputs []base.Input
+}
+
+func NewSlots(controls *Controls, deadzone float64) *Slots {
+	s := &Slots{
+		Deadzone:  deadzone,
+		keyboards: make([]*KeyboardController, len(controls.Players)),
+		pads:      make([]*GamepadController, len(controls.Players)),
+		inputs:    make([]base.Input, len(controls.Players)),
+	}
+	s.SetControls(controls)
+	return s
+}
+
+func (s *Slots) Controls() *Controls {
+	return s.controls
+}
+
+// SetControls rebinds every slot, including the gamepads already plugged in.
+func (s *Slots) SetControls(controls *Controls) {
+	s.controls = controls
+	for i := range s.keyboards {
+		keys := controls.Players[i].Keys
+		s.keyboards[i] = NewKeyboardController(
+			keys[ActionLeft],
+			keys[ActionRight],
+			keys[ActionUp],
+			keys[ActionDown],
+			keys[ActionAttack],
+			keys[ActionBlock],
+		)
+	}
+	for i, pad := range s.pads {
+		if pad != nil {
+			s.bindPad(i, pad)
+		}
+	}
+}
+
+func (s *Slots) bindPad(slot int, pad *GamepadController) {
+	buttons := s.controls.Players[slot].Buttons
+	pad.Left = ebiten.StandardGamepadButton(buttons[ActionLeft])
+	pad.Right = ebiten.StandardGamepadButton(buttons[ActionRight])
+	pad.Up = ebiten.StandardGamepadButton(buttons[ActionUp])
+	pad.Down = ebiten.StandardGamepadButton(buttons[ActionDown])
+	pad.Attack = ebiten.StandardGamepadButton(buttons[ActionAttack])
+	pad.Block = ebiten.StandardGamepadButton(buttons[ActionBlock])
+}
+
+// Update picks up gamepads that were plugged in or out since the last tick
+// and reads every slot for this tick.
+func (s *Slots) Update() {
+	s.connected = ebiten.AppendGamepadIDs(s.connected[:0])
+
+	for i, pad := range s.pads {
+		if pad != nil && !slices.Contains(s.connected, pad.ID) {
+			s.pads[i] = nil
+		}
+	}
+
+	for _, id := range s.connected {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) || s.assigned(id) {
+			continue
+		}
+		for i, pad := range s.pads {
+			if pad == nil {
+				s.pads[i] = NewGamepadController(id, s.Deadzone)
+				s.bindPad(i, s.pads[i])
+				break
+			}
+		}
+	}
+
+	for i := range s.inputs {
+		if pad := s.pads[i]; pad != nil {
+			s.inputs[i] = pad.GetInput()
+		} else {
+			s.inputs[i] = s.keyboards[i].GetInput()
+		}
+	}
+}
+
+func (s *Slots) assigned(id ebiten.GamepadID) bool {
+	for _, pad := range s.pads {
+		if pad != nil && pad.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Slots) Len() int {
+	return len(s.keyboards)
+}
+
+// Connected counts the keyboard and every gamepad currently plugged in.
+func (s *Slots) Connected() int {
+	return 1 + len(s.connected)
+}
+
+// Gamepad returns the gamepad holding the slot, nil when it is on keyboard.
+func (s *Slots) Gamepad(slot int) *GamepadController {
+	return s.pads[slot]
+}
+
+// StartHeld reports whether Start is held on any gamepad holding a slot. Start
+// pauses the game, so it can't be bound to an action.
+func (s *Slots) StartHeld() bool {
+	for _, pad := range s.pads {
+		if pad != nil && ebiten.IsStandardGamepadButtonPressed(pad.ID, ebiten.StandardGamepadButtonCenterRight) {
+			return true
+		}
+	}
+	return false
+}
+
+// Slot returns a controller that gives what the device holding the slot read
+// in the last Update, so a pad plugged in mid-match starts playing straight
+// away and everyone reading the slot in a tick sees the same input.
+func (s *Slots) Slot(slot int) base.Controller {
+	return &slotController{slots: s, slot: slot}
+}
+
+type slotController struct {
+	slots *Slots
+	slot  int
+}
+
+func (c *slotController) GetInput() base.Input {
+	return c.slots.inputs[c.slot]
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gassyrdaulet/go-fighting-game/base/ui"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+	"github.com/gassyrdaulet/go-fighting-game/controllers"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// controlsScreen is where players rebind their keys and gamepad buttons. The
+// list holds every action of every slot; confirming one waits for the next
+// key or button and binds it unless another action already uses it.
+type controlsScreen struct {
+	list    *ui.List
+	status  *ui.Label
+	gamepad bool
+	waiting bool
+	message string
+}
+
+// controlRows is how many actions the list shows before it scrolls.
+const controlRows = 12
+
+func (g *Game) initControls() {
+	s := controlsScreen{}
+
+	device := ui.NewSlider("Rebind", 0, 0, 1, func(v int) {
+		g.controls.gamepad = v == 1
+	})
+	device.Format = func(v int) string {
+		if v == 1 {
+			return "Gamepad"
+		}
+		return "Keyboard"
+	}
+	s.list = ui.NewList(make([]string, len(g.slots.Controls().Players)*len(controllers.Actions)), g.rebind)
+	s.list.Rows = controlRows
+	s.status = ui.NewLabel("")
+
+	g.controls = s
+	g.refreshControls()
+
+	menu := ui.NewContainer(ui.Vertical, menuSpacing,
+		ui.NewLabel("CONTROLS\n"),
+		device,
+		s.list,
+		ui.NewButton("Back", g.leaveControls),
+		s.status,
+	)
+	menu.OnBack = g.leaveControls
+	g.openMenu(menu, 20, 10)
+	menu.Focus(2)
+}
+
+func (g *Game) leaveControls() {
+	g.state.ChangeState(StateMainMenu)
+}
+
+// rebind starts waiting for a key or button for row i of the list.
+func (g *Game) rebind(i int) {
+	s := &g.controls
+	slot := i / len(controllers.Actions)
+
+	s.message = ""
+	if s.gamepad && g.slots.Gamepad(slot) == nil {
+		s.message = fmt.Sprintf("Plug in a gamepad for P%d first", slot+1)
+		return
+	}
+	s.waiting = true
+}
+
+func (g *Game) updateControls() {
+	s := &g.controls
+
+	if s.waiting {
+		if g.nav.Pause {
+			s.waiting = false
+			s.message = ""
+		} else {
+			i := s.list.Selected
+			slot, action := i/len(controllers.Actions), controllers.Actions[i%len(controllers.Actions)]
+			if s.gamepad {
+				g.bindButton(slot, action)
+			} else {
+				g.bindKey(slot, action)
+			}
+		}
+	} else {
+		g.updateMenu()
+	}
+
+	g.refreshControls()
+}
+
+// refreshControls writes the current bindings into the list, with brackets
+// round the ones being rebound, and what the player should do next below it.
+func (g *Game) refreshControls() {
+	s := &g.controls
+	controls := g.slots.Controls()
+
+	for slot, p := range controls.Players {
+		for i, action := range controllers.Actions {
+			key, button := p.Keys[action].String(), p.Buttons[action].String()
+			if s.gamepad {
+				button = "[" + button + "]"
+			} else {
+				key = "[" + key + "]"
+			}
+			s.list.Items[slot*len(controllers.Actions)+i] = fmt.Sprintf("P%d %-7s %-14s %s", slot+1, action, key, button)
+		}
+	}
+
+	text := ""
+	if s.waiting {
+		text += "Press a key or button, [Esc/Start] to cancel\n"
+	} else {
+		text += "[Attack/Enter] Rebind  [Block/Esc] Back\n"
+	}
+	if s.message != "" {
+		text += s.message + "\n"
+	}
+	for _, c := range controls.Conflicts() {
+		text += "! " + c.String() + "\n"
+	}
+	s.status.Text = text
+}
+
+func (g *Game) bindKey(slot int, action controllers.Action) {
+	s := &g.controls
+	controls := g.slots.Controls()
+
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		if key == ebiten.KeyEscape || key == ebiten.KeyF11 {
+			continue
+		}
+		if other, otherAction, ok := controls.KeyOwner(key, slot, action); ok {
+			s.message = fmt.Sprintf("%s is already P%d %s", key, other+1, otherAction)
+			return
+		}
+		controls.Players[slot].Keys[action] = key
+		g.saveControls()
+		return
+	}
+}
+
+func (g *Game) bindButton(slot int, action controllers.Action) {
+	s := &g.controls
+	controls := g.slots.Controls()
+
+	pad := g.slots.Gamepad(slot)
+	if pad == nil {
+		s.waiting = false
+		s.message = fmt.Sprintf("P%d gamepad was unplugged", slot+1)
+		return
+	}
+
+	for _, b := range inpututil.AppendJustPressedStandardGamepadButtons(pad.ID, nil) {
+		if b == ebiten.StandardGamepadButtonCenterRight {
+			continue
+		}
+		button := controllers.GamepadButton(b)
+		if otherAction, ok := controls.ButtonOwner(button, slot, action); ok {
+			s.message = fmt.Sprintf("%s is already P%d %s", button, slot+1, otherAction)
+			return
+		}
+		controls.Players[slot].Buttons[action] = button
+		g.saveControls()
+		return
+	}
+}
+
+// saveControls applies the bindings straight away and writes them to the
+// controls file so they survive a restart.
+func (g *Game) saveControls() {
+	s := &g.controls
+	s.waiting = false
+	s.message = ""
+
+	g.slots.SetControls(g.slots.Controls())
+	if err := controllers.SaveControls(constants.ControlsFile, g.slots.Controls()); err != nil {
+		log.Println(err)
+		s.message = "Could not save " + constants.ControlsFile
+	}
+}
+
+func (g *Game) drawControls(screen *ebiten.Image) {
+	g.menu.Draw(screen)
+}
@@ -1,21 +1,18 @@
 package actor
 
 import (
-	"fmt"
 	"image"
-	"image/color"
+	"math"
 
 	"github.com/gassyrdaulet/go-fighting-game/base"
 	"github.com/gassyrdaulet/go-fighting-game/base/physics"
 	"github.com/gassyrdaulet/go-fighting-game/characters"
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
 )
 
 type AnimationName string
 
 const (
-	debug	   bool 		 = true
 	Idle       AnimationName = "idle"
 	Run        AnimationName = "run"
 	Jump       AnimationName = "jump"
@@ -24,9 +21,14 @@ const (
 	JumpAttack AnimationName = "jump_attack"
 	FallAttack AnimationName = "fall_attack"
 	RunAttack  AnimationName = "run_attack"
+	Squat      AnimationName = "squat"
+	SquatAttack AnimationName = "squat_attack"
 	ChargeJump AnimationName = "charging_jump"
 	Dying	   AnimationName = "dying"
 	Hurting	   AnimationName = "hurt"
+	Block      AnimationName = "block"
+	Parry      AnimationName = "parry"
+	Stunned    AnimationName = "stunned"
 	None	   AnimationName = "none"
 )
 
@@ -34,7 +36,9 @@ type Actor struct {
 	*physics.Body
 	*base.Animator
 	Character         		*characters.Character
-	Controller        		base.Controller
+	// Team is who the actor fights alongside. Teammates only hurt each
+	// other with friendly fire on.
+	Team					int
 	MaxHp           		int
 	Hp              		int
 	Dead 			  		bool
@@ -57,6 +61,29 @@ type Actor struct {
 	HurtingTicksMax			int
 	HurtingTicks			int
 	AttackRange				float64
+	Crouching				bool
+	CrouchHeight			float64
+	ComboIndex				int
+	ComboQueued				bool
+	HitMask					uint64
+	Blocking				bool
+	BlockTicks				int
+	Guard					float64
+	ParryTicks				int
+	Stunned					bool
+	StunTicks				int
+	// Shot is the projectile thrown this tick, for the simulation to spawn.
+	Shot					*characters.Projectile
+	Fired					bool
+	// Stocks are the lives left in the round. LastHitBy is who gets the KO
+	// when the actor dies, and DamageDealt, KOs, Falls and RoundsWon add up
+	// over the whole match.
+	Stocks					int
+	LastHitBy				*Actor
+	DamageDealt				int
+	KOs						int
+	Falls					int
+	RoundsWon				int
 }
 
 func (a *Actor) GoLeft() {
@@ -77,29 +104,261 @@ func (a *Actor) GoRight() {
 	a.Direction = 1
 }
 
+// TakeDamage hurts the actor unless it is already hurting; only the
+// follow-up hits of a combo land on a target still reeling from the last one.
+// When from is set, the hit it is landing knocks the actor away from it and
+// decides how long the actor stays hurt.
 func (a *Actor) TakeDamage(amount int, from *Actor) {
-	if a.Dying || a.Dead || a.Hurting {
+	if a.Hurting && (from == nil || from.ComboIndex == 0) {
 		return
 	}
+	if from == nil {
+		a.hurt(characters.Hit{Damage: amount}, nil, 0)
+		return
+	}
+	hit := from.Hit()
+	hit.Damage = amount
+	a.TakeHit(hit, from, a.awayFrom(from))
+}
+
+// TakeHit lands hit on the actor, knocking it towards dir. An actor blocking
+// the way the hit comes from takes it on its guard. from is who landed it,
+// for parries and the match stats; it is nil for damage that comes from no one.
+func (a *Actor) TakeHit(hit characters.Hit, from *Actor, dir float64) {
+	if a.Dying || a.Dead {
+		return
+	}
+	if from != nil {
+		a.LastHitBy = from
+	}
+	if a.Blocking && dir*float64(a.Direction) <= 0 {
+		a.block(hit, from, dir)
+		return
+	}
+	a.hurt(hit, from, dir)
+}
+
+func (a *Actor) hurt(hit characters.Hit, from *Actor, dir float64) {
+	if a.Dying || a.Dead {
+		return
+	}
+	from.dealt(min(hit.Damage, a.Hp))
 	a.VX = 0
+	a.Blocking = false
+	a.BlockTicks = 0
+	a.Crouching = false
 	a.Hurting = true
 	a.HurtingTicks = a.HurtingTicksMax
-	a.Hp -= amount
+	a.Hp -= hit.Damage
+	if a.Hp <= 0 {
+		a.Hp = 0
+		a.Die()
+		return
+	}
+
+	if hit.Hitstun > 0 {
+		a.HurtingTicks = hit.Hitstun
+	}
+	growth := 1 + hit.KnockbackGrowth*float64(a.MaxHp-a.Hp)
+	a.Knockback(hit.KnockbackX*dir*growth, hit.KnockbackY*growth)
+}
+
+// block takes a hit on the guard. Right after the guard goes up the hit is
+// parried: nothing gets through and the attacker is left stunned.
+func (a *Actor) block(hit characters.Hit, from *Actor, dir float64) {
+	if a.BlockTicks <= a.Character.ParryTicks {
+		a.ParryTicks = parryTicks
+		if from != nil && !hit.Ranged {
+			from.Stun(a.Character.ParryStunTicks)
+		}
+		return
+	}
+
+	damage := int(math.Round(float64(hit.Damage) * a.Character.BlockDamage))
+	from.dealt(min(damage, a.Hp))
+	a.Hp -= damage
 	if a.Hp <= 0 {
 		a.Hp = 0
 		a.Die()
+		return
+	}
+	a.Knockback(hit.KnockbackX*dir*a.Character.BlockKnockback, 0)
+
+	a.Guard -= float64(hit.Damage)
+	if a.Guard <= 0 {
+		a.Guard = 0
+		a.Stun(a.Character.GuardBreakTicks)
+	}
+}
+
+// dealt counts damage the actor did to someone, nothing when there is no
+// actor to count it for.
+func (a *Actor) dealt(damage int) {
+	if a != nil && damage > 0 {
+		a.DamageDealt += damage
+	}
+}
+
+// Stun leaves the actor helpless for a while, dropping whatever it was doing.
+func (a *Actor) Stun(ticks int) {
+	if a.Dying || a.Dead {
+		return
+	}
+	a.Stunned = true
+	a.StunTicks = ticks
+	a.Attacking = false
+	a.AttackTicks = 0
+	a.resetCombo()
+	a.Blocking = false
+	a.BlockTicks = 0
+	a.Crouching = false
+	a.ChargingJump = false
+	a.ChargingJumpTicks = 0
+	a.VX = 0
+}
+
+// awayFrom is the direction pointing from other to the actor.
+func (a *Actor) awayFrom(other *Actor) float64 {
+	if a.X > other.X {
+		return 1
+	} else if a.X < other.X {
+		return -1
+	}
+	return float64(other.Direction)
+}
+
+// Knockback pushes the actor, the heavier it is the less. A push upwards
+// lifts it off the ground.
+func (a *Actor) Knockback(vx, vy float64) {
+	if a.Weight > 0 {
+		vx /= a.Weight
+		vy /= a.Weight
+	}
+	a.VX = vx
+	if vy != 0 {
+		a.VY = vy
+		a.OnGround = false
+	}
+}
+
+// touchHazards lets the worst of the hazards the actor touches have its way.
+// Its damage lands on every tick of contact. The knockback and the hitstun
+// only come with it when the actor isn't hurting already, so standing in a
+// hazard drains the actor instead of pinning it down in hitstun.
+func (a *Actor) touchHazards(contacts []physics.Contact) {
+	if a.Dying || a.Dead {
+		return
+	}
+	var worst *physics.Contact
+	for i, c := range contacts {
+		if !c.IsHazard() {
+			continue
+		}
+		if worst == nil || c.Hazard.Kill && !worst.Hazard.Kill ||
+			c.Hazard.Kill == worst.Hazard.Kill && c.Hazard.Damage > worst.Hazard.Damage {
+			worst = &contacts[i]
+		}
+	}
+	if worst == nil {
+		return
+	}
+
+	if worst.Hazard.Kill {
+		a.Hp = 0
+		a.Die()
+		return
+	}
+	if a.Hurting {
+		a.Hp -= min(worst.Hazard.Damage, a.Hp)
+		if a.Hp == 0 {
+			a.Die()
+		}
+		return
+	}
+	a.TakeDamage(worst.Hazard.Damage, nil)
+	if a.Dying {
+		return
+	}
+
+	dir := -float64(a.Direction)
+	if center := (float64(worst.TileX) + 0.5) * constants.TileSize; a.X > center {
+		dir = 1
+	} else if a.X < center {
+		dir = -1
+	}
+	a.Knockback(worst.Hazard.KnockbackX*dir, worst.Hazard.KnockbackY)
+}
+
+// An actor falling onto someone's head at stompSpeed or faster stomps on it,
+// bouncing back up at stompBounce, instead of standing on it.
+const (
+	stompSpeed  = 4
+	stompBounce = -6
+)
+
+var stompHit = characters.Hit{Damage: 6, Hitstun: 20}
+
+// stomp lands a stomp on whoever the actor came down hard on. Allies are
+// bounced off without getting hurt unless there is friendly fire.
+func (a *Actor) stomp(contacts []physics.Contact, players []*Actor, friendlyFire bool) {
+	if a.Dying || a.Dead {
+		return
 	}
+	for _, c := range contacts {
+		if c.Body == nil || c.Speed < stompSpeed {
+			continue
+		}
+		a.VY = stompBounce
+		a.OnGround = false
+		for _, other := range players {
+			if other.Body == c.Body && (friendlyFire || !a.Ally(other)) {
+				other.TakeHit(stompHit, a, other.awayFrom(a))
+			}
+		}
+		return
+	}
+}
+
+// parryTicks is how long a successful parry shows.
+const parryTicks = 12
+
+// knockbackFriction slows down an actor knocked back, the ground much more
+// than the air, so launched actors fly far.
+func knockbackFriction(onGround bool) float64 {
+	if onGround {
+		return 0.8
+	}
+	return 0.97
 }
 
 func (a *Actor) Die() {
 	if !a.Dead && !a.Dying {
 		a.VY = 0
 		a.VX = 0
+		a.Crouching = false
 		a.Dying = true
 		a.DyingTicks = a.DyingTicksMax
 	}
 }
 
+// Crouch keeps the actor in place, only turning it to face the way it is
+// pressed.
+func (a *Actor) Crouch(input base.Input) {
+	a.VX = 0
+	if input.Left {
+		a.Direction = -1
+	} else if input.Right {
+		a.Direction = 1
+	}
+}
+
+// DropDown lets the actor fall through the platform it stands on.
+func (a *Actor) DropDown() {
+	if a.OnGround && !a.Attacking {
+		a.DropThrough = true
+	}
+}
+
 func (a *Actor) ChargeJump() {
 	if a.OnGround && !a.ChargingJump && !a.Attacking {
 		a.ChargingJump = true
@@ -116,32 +375,136 @@ func (a *Actor) ChargeJump() {
 func (a *Actor) StartAttack() {
 	if a.AttackCooldownTicks <= 0 && a.AttackTicks <= 0 && !a.Attacking {
 		a.Attacking = true
-		a.AttackTicks = a.AttackTicksMax
+		a.startHit(0)
+	}
+}
+
+// QueueCombo chains the next hit of the combo when Attack is pressed again
+// inside the current hit's chain window. Only ground attacks chain, jump and
+// crouch attacks are single hits.
+func (a *Actor) QueueCombo() {
+	if !a.Attacking || a.ComboQueued || a.ComboIndex+1 >= len(a.Character.Combo) {
+		return
+	}
+	if !a.OnGround || a.Crouching || a.AttackTicks > a.Hit().ChainTicks {
+		return
 	}
+	a.ComboQueued = true
+}
+
+// Hit is the combo hit in progress, or the opening hit when not attacking.
+func (a *Actor) Hit() characters.Hit {
+	return a.Character.Combo[a.ComboIndex]
 }
 
-func (a *Actor) Attack(others []*Actor) {
-	damage := a.Character.Damage
-	hitbox := a.AttackHitBox()
-	for _, o := range others {
-		if o == a || o.Dead {
+func (a *Actor) startHit(index int) {
+	a.ComboIndex = index
+	a.ComboQueued = false
+	a.HitMask = 0
+	a.Fired = false
+	hit := a.Hit()
+	a.AttackTicksMax = hit.Ticks
+	a.AttackTicks = hit.Ticks
+	a.AttackRange = hit.Range
+}
+
+func (a *Actor) resetCombo() {
+	a.ComboIndex = 0
+	a.ComboQueued = false
+	a.HitMask = 0
+	a.Fired = false
+	a.AttackTicksMax = a.Hit().Ticks
+	a.AttackRange = a.Hit().Range
+}
+
+// Attack lands the hit in progress on everyone its hitboxes overlap this
+// tick, each actor at most once per hit. Teammates are spared unless
+// friendlyFire is on. A parry ends the attack, so nobody after the one who
+// parried it gets hit.
+func (a *Actor) Attack(others []*Actor, friendlyFire bool) {
+	hitboxes := a.HitBoxes()
+	if len(hitboxes) == 0 {
+		return
+	}
+	for i, o := range others {
+		if o == a || o.Dead || a.HitMask&(1<<i) != 0 {
 			continue
 		}
-		if hitbox.Overlaps(o.HitBox()) {
-			o.TakeDamage(damage, a)
+		if !friendlyFire && a.Ally(o) {
+			continue
+		}
+		if overlapsAny(hitboxes, o.HurtBoxes()) {
+			a.HitMask |= 1 << i
+			o.TakeDamage(a.Hit().Damage, a)
+			if !a.Attacking {
+				return
+			}
+		}
+	}
+}
+
+// Ally tells whether other is on the actor's team.
+func (a *Actor) Ally(other *Actor) bool {
+	return a.Team == other.Team
+}
+
+// shoot throws the projectile of the hit in progress when the hit becomes
+// active: on its first active frame, or when it runs out without frame data.
+func (a *Actor) shoot() {
+	if !a.Attacking || a.Fired {
+		return
+	}
+	def := a.Character.Projectiles[a.Hit().Projectile]
+	if def == nil {
+		return
+	}
+	if fd, frame := a.frameData(); fd != nil {
+		if !fd.IsActive(frame) {
+			return
+		}
+	} else if a.AttackTicks > 0 {
+		return
+	}
+	a.Fired = true
+	a.Shot = def
+}
+
+// HitBoxes are where the attack in progress hits this tick. Animations with
+// frame data hit with the boxes of their active frames; the rest hit once,
+// with AttackHitBox, on the tick the attack runs out.
+func (a *Actor) HitBoxes() []image.Rectangle {
+	if !a.Attacking {
+		return nil
+	}
+	if fd, frame := a.frameData(); fd != nil {
+		return a.boxes(fd.HitboxesAt(frame))
+	}
+	if a.AttackTicks == 0 {
+		return []image.Rectangle{a.AttackHitBox()}
+	}
+	return nil
+}
+
+// HurtBoxes are where the actor can be hit this tick: the boxes of the
+// current frame when its animation has them, the body otherwise.
+func (a *Actor) HurtBoxes() []image.Rectangle {
+	if fd, frame := a.frameData(); fd != nil {
+		if boxes := fd.HurtboxesAt(frame); len(boxes) > 0 {
+			return a.boxes(boxes)
 		}
 	}
-	a.AttackCooldownTicks = a.AttackCooldownTicksMax
+	return []image.Rectangle{a.HitBox()}
 }
 
 func (a *Actor) AttackHitBox() image.Rectangle {
-	dir := a.Direction
+	x := a.X + a.Character.Width/2
+	if a.Direction < 0 {
+		x = a.X - a.Character.Width/2 - a.AttackRange
+	}
 
-	x := a.X + (a.Character.Width/2)*float64(dir)
-	
 	return image.Rect(
 		int(x),
-		int(a.Y+5),
+		int(a.hurtTop()+5),
 		int(x+a.AttackRange),
 		int(a.Y+a.Character.Height-5),
 	)
@@ -149,31 +512,112 @@ func (a *Actor) AttackHitBox() image.Rectangle {
 
 func (a *Actor) HitBox() image.Rectangle {
 	return image.Rect(
-		int(a.X),
-		int(a.Y),
-		int(a.X+a.Character.Width),
+		int(a.X-a.Character.Width/2),
+		int(a.hurtTop()),
+		int(a.X+a.Character.Width/2),
 		int(a.Y+a.Character.Height),
 	)
 }
 
-func (a *Actor) Update(world *physics.World, players []*Actor, friendlyFire bool) {
-	if !a.ChargingJump && !a.Hurting && !a.Dying && !a.Dead {
-		input := a.Controller.GetInput()
-		if input.Left {
+// frameData returns the frame data of the current animation and the frame it
+// is on, nil when the animation has none.
+func (a *Actor) frameData() (*characters.FrameData, int) {
+	fd := a.Character.FrameData[a.CurrentAnimation]
+	anim := a.Animations[a.CurrentAnimation]
+	if fd == nil || anim == nil {
+		return nil, 0
+	}
+	return fd, anim.FrameIndex
+}
+
+// boxes places frame data boxes around the actor, mirrored when it faces
+// left.
+func (a *Actor) boxes(boxes []characters.Box) []image.Rectangle {
+	rects := make([]image.Rectangle, 0, len(boxes))
+	for _, b := range boxes {
+		x0, x1 := a.X+b.X, a.X+b.X+b.W
+		if a.Direction < 0 {
+			x0, x1 = a.X-b.X-b.W, a.X-b.X
+		}
+		rects = append(rects, image.Rect(int(x0), int(a.Y+b.Y), int(x1), int(a.Y+b.Y+b.H)))
+	}
+	return rects
+}
+
+func overlapsAny(a, b []image.Rectangle) bool {
+	for _, r := range a {
+		for _, o := range b {
+			if r.Overlaps(o) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hurtTop is where the actor can be hit from: the top of the body, or lower
+// down while crouching.
+func (a *Actor) hurtTop() float64 {
+	if a.Crouching {
+		return a.Y + a.Character.Height - a.CrouchHeight
+	}
+	return a.Y
+}
+
+func (a *Actor) Update(input base.Input, world *physics.World, players []*Actor, friendlyFire bool) {
+	a.Shot = nil
+	if !a.ChargingJump && !a.Hurting && !a.Stunned && !a.Dying && !a.Dead {
+		if !a.Attacking {
+			a.Blocking = input.Block && a.OnGround && a.Guard > 0
+			a.Crouching = a.OnGround && input.Down && !input.Up && !a.Blocking
+		}
+		if a.Blocking {
+			// The guard stays up for as long as it is held, nothing else
+			// can be done behind it.
+			a.BlockTicks++
+			a.VX *= knockbackFriction(a.OnGround)
+			input.Attack, input.Up = false, false
+		} else if a.Crouching {
+			a.Crouch(input)
+		} else if input.Left {
 			a.GoLeft()
 		} else if input.Right {
 			a.GoRight()
 		} else {
 			a.VX = 0
 		}
+		if !a.Blocking {
+			a.BlockTicks = 0
+		}
 		if input.Attack {
-			a.StartAttack()
+			if a.Attacking {
+				a.QueueCombo()
+			} else {
+				a.StartAttack()
+			}
 		}
-		if input.Up {
+		if input.Up && input.Down {
+			a.DropDown()
+		} else if input.Up {
 			a.ChargeJump()
 		}
 	}
 
+	if !a.Blocking && a.Guard < a.Character.Guard {
+		a.Guard = min(a.Guard+a.Character.GuardRegen, a.Character.Guard)
+	}
+	if a.ParryTicks > 0 {
+		a.ParryTicks--
+	}
+	if a.StunTicks > 0 {
+		a.StunTicks--
+	} else {
+		a.Stunned = false
+	}
+	if a.Stunned {
+		a.VX *= knockbackFriction(a.OnGround)
+	}
+
 	if a.AttackCooldownTicks > 0 {
 		a.AttackCooldownTicks--
 	}
@@ -181,13 +625,23 @@ func (a *Actor) Update(world *physics.World, players []*Actor, friendlyFire bool
 		a.AttackTicks--
 	} else {
 		if a.Attacking {
-			if friendlyFire {
-				a.Attack(players)
-			} 
-			a.AttackTicks = 0
-			a.Attacking = false
+			a.Attack(players, friendlyFire)
+			a.shoot()
+			if a.ComboQueued && !a.Hurting && !a.Dying {
+				a.startHit(a.ComboIndex + 1)
+				// Restart the animation even if the next hit reuses it.
+				a.CurrentAnimation = ""
+			} else {
+				a.AttackTicks = 0
+				a.Attacking = false
+				a.AttackCooldownTicks = a.AttackCooldownTicksMax
+				a.resetCombo()
+			}
 		}
 	}
+	if a.Hurting {
+		a.VX *= knockbackFriction(a.OnGround)
+	}
 	if a.HurtingTicks > 0 {
 		a.HurtingTicks--
 	} else {
@@ -215,9 +669,16 @@ func (a *Actor) Update(world *physics.World, players []*Actor, friendlyFire bool
 		}
 	}
 
-	world.Step(a)
+	contacts := world.Step(a)
+	a.touchHazards(contacts)
+	a.stomp(contacts, players, friendlyFire)
 
 	a.UpdateFrame(string(a.UpdateAnimation()))
+
+	if a.Attacking {
+		a.Attack(players, friendlyFire)
+	}
+	a.shoot()
 }
 
 func (a *Actor) UpdateAnimation() AnimationName {
@@ -230,24 +691,45 @@ func (a *Actor) UpdateAnimation() AnimationName {
 	if a.Hurting {
 		return Hurting
 	}
+	if a.Stunned {
+		return Stunned
+	}
+	if a.ParryTicks > 0 {
+		return Parry
+	}
+	if a.Blocking {
+		return Block
+	}
 	if a.ChargingJumpTicks > 0 {
 		return ChargeJump
 	}
 
 	if a.Attacking {
+		hit := a.Hit()
 		switch a.CurrentAnimation {
-		case string(Attack), string(RunAttack), string(JumpAttack):
+		case hit.Animation, hit.RunAnimation:
 			return AnimationName(a.CurrentAnimation)
-		default:
-			if a.OnGround {
-				if a.VX == 0 {
-					return Attack
-				}
-				return RunAttack
-			} else {
+		case string(JumpAttack), string(SquatAttack):
+			if a.ComboIndex == 0 {
+				return AnimationName(a.CurrentAnimation)
+			}
+		}
+		if a.ComboIndex == 0 {
+			if a.Crouching {
+				return SquatAttack
+			}
+			if !a.OnGround {
 				return JumpAttack
 			}
 		}
+		if a.VX == 0 {
+			return AnimationName(hit.Animation)
+		}
+		return AnimationName(hit.RunAnimation)
+	}
+
+	if a.Crouching {
+		return Squat
 	}
 
 	if a.OnGround {
@@ -263,40 +745,6 @@ func (a *Actor) UpdateAnimation() AnimationName {
 	return Fall
 }
 
- 
-func (a *Actor) Draw(screen *ebiten.Image, sx, sy float64) {
-	if debug {
-		a.DrawDebug(screen, sx, sy)
-	}
-	a.DrawHPBar(screen, sx, sy)
-	a.DrawFrame(screen, sx, sy, a.Direction == -1)
-}
-
 func (a *Actor) IsAlive() bool{
 	return !a.Dead
 }
-
-func (a *Actor) DrawHPBar(screen *ebiten.Image, sx, sy float64) {
-	w := 30
-	h := 4
-
-	ratio := float64(a.Hp) / float64(a.MaxHp)
-	newWidth := int(float64(w)*ratio)
-	if newWidth > 0 {
-		bar := ebiten.NewImage(newWidth, h)
-		bar.Fill(color.RGBA{200, 45, 45, 255})
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(sx-float64(w/2), sy-10)
-
-		screen.DrawImage(bar, op)
-	}
-}
-
-func (a *Actor) DrawDebug(screen *ebiten.Image, sx, sy float64) {
-	ebitenutil.DebugPrintAt(
-		screen,
-		fmt.Sprintf("Animation & Attacking: (%s, %t)", a.CurrentAnimation, a.Attacking),
-		int(sx-30),
-		int(sy-30),
-	)
-}
\ No newline at end of file
@@ -4,16 +4,13 @@ import (
 	b "github.com/gassyrdaulet/go-fighting-game/base"
 	p "github.com/gassyrdaulet/go-fighting-game/base/physics"
 	"github.com/gassyrdaulet/go-fighting-game/characters"
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
-func NewActor(x, y float64, controller b.Controller, direction int, char *characters.Character) *Actor {
+func NewActor(x, y float64, direction int, char *characters.Character) *Actor {
 	animCopy := make(map[string]*b.Animation)
     for k, v := range char.Animations {
-        framesCopy := make([]*ebiten.Image, len(v.Frames))
-        copy(framesCopy, v.Frames)
         animCopy[k] = &b.Animation{
-            Frames:     framesCopy,
+            FrameCount: v.FrameCount,
             FrameSpeed: v.FrameSpeed,
             Loop:       v.Loop,
             XO:         v.XO,
@@ -31,17 +28,41 @@ func NewActor(x, y float64, controller b.Controller, direction int, char *charac
 		},
 		Animator: b.NewAnimator(animCopy),
 		Character:   char,
-		Controller: controller,
 		MaxHp:          char.MaxHP,
 		Hp:          char.MaxHP,
 		Speed:          char.Speed,
 		JumpForce:          char.JumpForce,
 		Direction:   direction,
-		AttackTicksMax: char.AttackTicks,
+		AttackTicksMax: char.Combo[0].Ticks,
 		AttackCooldownTicksMax: char.AttackCooldownTicks,
 		ChargingJumpTicksMax: char.ChargingJumpTicks,
 		DyingTicksMax: char.DyingTicks,
 		HurtingTicksMax: char.HurtingTicks,
-		AttackRange: char.AttackRange,
+		AttackRange: char.Combo[0].Range,
+		CrouchHeight: char.CrouchHeight,
+		Guard: char.Guard,
+		Stocks: 1,
 	}
 }
+
+// Respawn brings the actor back fresh at x, y, facing right like at the start
+// of the match, keeping its team and what it has done in the match so far.
+func (a *Actor) Respawn(x, y float64) {
+	fresh := NewActor(x, y, 1, a.Character)
+	fresh.Team = a.Team
+	fresh.Stocks = a.Stocks
+	fresh.DamageDealt = a.DamageDealt
+	fresh.KOs = a.KOs
+	fresh.Falls = a.Falls
+	fresh.RoundsWon = a.RoundsWon
+
+	body, animator := a.Body, a.Animator
+	*body = *fresh.Body
+	for name, anim := range fresh.Animations {
+		*animator.Animations[name] = *anim
+	}
+	animator.CurrentAnimation = ""
+
+	*a = *fresh
+	a.Body, a.Animator = body, animator
+}
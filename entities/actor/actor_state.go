@@ -0,0 +1,31 @@
+package actor
+
+import (
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/base/physics"
+)
+
+// State is a snapshot of an actor that Restore can roll it back to.
+type State struct {
+	actor    Actor
+	body     physics.Body
+	animator base.AnimatorState
+}
+
+func (a *Actor) Save() State {
+	return State{
+		actor:    *a,
+		body:     a.Body.Save(),
+		animator: a.Animator.Save(),
+	}
+}
+
+func (a *Actor) Restore(state State) {
+	body, animator := a.Body, a.Animator
+
+	*a = state.actor
+	a.Body, a.Animator = body, animator
+
+	a.Body.Restore(state.body)
+	a.Animator.Restore(state.animator)
+}
@@ -0,0 +1,128 @@
+package actor_test
+
+import (
+	"testing"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/base/physics"
+	"github.com/gassyrdaulet/go-fighting-game/characters"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+	"github.com/gassyrdaulet/go-fighting-game/entities/actor"
+	"github.com/gassyrdaulet/go-fighting-game/simulation/simtest"
+)
+
+func loadCharacter(t *testing.T) *characters.Character {
+	t.Helper()
+
+	for _, c := range simtest.Characters(t) {
+		return c
+	}
+	t.Fatal("no characters")
+	return nil
+}
+
+// newFloorWorld is an empty box with a solid floor along the bottom row.
+func newFloorWorld() *physics.World {
+	const solid = 2
+	tiles := base.NewTileMap(8, 6, constants.TileSize)
+	tiles.AddTileType(solid, base.Solid)
+	for x := range tiles.Width {
+		tiles.SetTile(x, 5, solid)
+	}
+	return physics.NewWorld(tiles)
+}
+
+// newHazardWorld is a floor with a row of hazard tiles lying on it.
+func newHazardWorld(hazard base.TileHazard) *physics.World {
+	const hazardID = 39
+	world := newFloorWorld()
+	world.Tiles.AddHazard(hazardID, hazard)
+	for x := range world.Tiles.Width {
+		world.Tiles.SetTile(x, 4, hazardID)
+	}
+	return world
+}
+
+func TestHazardDamagesEveryTick(t *testing.T) {
+	hazard := base.TileHazard{Damage: 2}
+	world := newHazardWorld(hazard)
+	a := actor.NewActor(4*constants.TileSize, 0, 1, loadCharacter(t))
+	a.Y = 5*constants.TileSize - a.Height
+	a.OnGround = true
+	players := []*actor.Actor{a}
+
+	const ticks = 20
+	for tick := range ticks {
+		hp := a.Hp
+		a.Update(base.Input{}, world, players, false)
+		if a.Hp != hp-hazard.Damage {
+			t.Fatalf("tick %d: hp went from %d to %d, want %d", tick, hp, a.Hp, hp-hazard.Damage)
+		}
+	}
+	if !a.Hurting {
+		t.Error("not hurting after standing in a hazard")
+	}
+}
+
+func TestHazardKnocksBackOnlyWhenItStartsHurting(t *testing.T) {
+	world := newHazardWorld(base.TileHazard{Damage: 1, KnockbackY: -6})
+	a := actor.NewActor(4*constants.TileSize, 0, 1, loadCharacter(t))
+	a.Y = 5*constants.TileSize - a.Height
+	a.OnGround = true
+	players := []*actor.Actor{a}
+
+	a.Update(base.Input{}, world, players, false)
+	if a.VY >= 0 {
+		t.Fatalf("VY = %v after touching the hazard, want it knocked up", a.VY)
+	}
+
+	launched := a.VY
+	a.Update(base.Input{}, world, players, false)
+	if a.VY <= launched {
+		t.Errorf("VY went from %v to %v, knocked back again while hurting", launched, a.VY)
+	}
+}
+
+func TestStomp(t *testing.T) {
+	tests := []struct {
+		name         string
+		team         int
+		friendlyFire bool
+		speed        float64
+		hurt, bounce bool
+	}{
+		{name: "enemy", team: 2, speed: 5, hurt: true, bounce: true},
+		{name: "ally", team: 1, speed: 5, bounce: true},
+		{name: "ally with friendly fire", team: 1, friendlyFire: true, speed: 5, hurt: true, bounce: true},
+		{name: "landing softly", team: 2, speed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			world := newFloorWorld()
+			char := loadCharacter(t)
+			below := actor.NewActor(4*constants.TileSize, 0, 1, char)
+			below.Y = 5*constants.TileSize - below.Height
+			below.OnGround = true
+			below.Team = 1
+			above := actor.NewActor(below.X, 0, 1, char)
+			above.Y = below.Y - above.Height - 1
+			above.VY = tt.speed
+			above.Team = tt.team
+			world.Bodies = []*physics.Body{above.Body, below.Body}
+			players := []*actor.Actor{above, below}
+
+			above.Update(base.Input{}, world, players, tt.friendlyFire)
+
+			if hurt := below.Hp < below.MaxHp; hurt != tt.hurt {
+				t.Errorf("hurt = %v (hp %d of %d), want %v", hurt, below.Hp, below.MaxHp, tt.hurt)
+			}
+			if bounced := above.VY < 0; bounced != tt.bounce {
+				t.Errorf("bounced = %v (VY %v), want %v", bounced, above.VY, tt.bounce)
+			}
+			if !tt.bounce && (!above.OnGround || above.Y+above.Height != below.Y) {
+				t.Errorf("feet at %v, on ground %v; want it standing on the head at %v", above.Y+above.Height, above.OnGround, below.Y)
+			}
+		})
+	}
+}
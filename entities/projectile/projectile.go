@@ -0,0 +1,119 @@
+package projectile
+
+import (
+	"image"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/base/physics"
+	"github.com/gassyrdaulet/go-fighting-game/characters"
+	"github.com/gassyrdaulet/go-fighting-game/entities/actor"
+)
+
+// Projectile is a thrown characters.Projectile in flight. It moves through the
+// world like an actor does, with its GravityScale as its weight, and breaks on
+// anything that would stop an actor: walls, floors, ceilings, the platforms
+// it comes down on and the moving ones in its way. It flies out of the world
+// at its edges.
+type Projectile struct {
+	*physics.Body
+	*base.Animator
+	Def       *characters.Projectile
+	Owner     int
+	Team      int
+	Direction int
+	Ticks     int
+	Dead      bool
+}
+
+// New throws def from players[owner] the way it is facing.
+func New(def *characters.Projectile, owner int, from *actor.Actor) *Projectile {
+	anim := from.Character.Animations[def.Animation]
+
+	return &Projectile{
+		Body: &physics.Body{
+			X:      from.X + def.OffsetX*float64(from.Direction),
+			Y:      from.Y + def.OffsetY,
+			VX:     def.Speed * float64(from.Direction),
+			VY:     def.SpeedY,
+			Width:  def.Width,
+			Height: def.Height,
+			Weight: def.GravityScale,
+		},
+		Animator: base.NewAnimator(map[string]*base.Animation{
+			def.Animation: {
+				FrameCount: anim.FrameCount,
+				FrameSpeed: anim.FrameSpeed,
+				Loop:       anim.Loop,
+				XO:         anim.XO,
+				YO:         anim.YO,
+			},
+		}),
+		Def:       def,
+		Owner:     owner,
+		Team:      from.Team,
+		Direction: from.Direction,
+		Ticks:     def.Lifetime,
+	}
+}
+
+func (p *Projectile) Update(world *physics.World, players []*actor.Actor, friendlyFire bool) {
+	if p.Dead {
+		return
+	}
+
+	if x := p.X + p.VX; x-p.Width/2 < 0 || x+p.Width/2 > world.Width {
+		p.Die()
+		return
+	}
+	for _, c := range world.Step(p) {
+		if c.Block != physics.NotBlocked {
+			p.Die()
+		}
+	}
+
+	p.UpdateFrame(p.Def.Animation)
+
+	if !p.Dead {
+		p.hit(players, friendlyFire)
+	}
+
+	p.Ticks--
+	if p.Ticks <= 0 {
+		p.Die()
+	}
+}
+
+// hit lands on the first actor it touches other than its owner, and other
+// than the owner's teammates without friendlyFire. Actors still hurting let
+// it through.
+func (p *Projectile) hit(players []*actor.Actor, friendlyFire bool) {
+	box := p.HitBox()
+	for i, a := range players {
+		if i == p.Owner || a.Dead || a.Dying || a.Hurting {
+			continue
+		}
+		if !friendlyFire && a.Team == p.Team {
+			continue
+		}
+		for _, r := range a.HurtBoxes() {
+			if box.Overlaps(r) {
+				a.TakeHit(p.Def.Hit, players[p.Owner], float64(p.Direction))
+				p.Die()
+				return
+			}
+		}
+	}
+}
+
+func (p *Projectile) HitBox() image.Rectangle {
+	return image.Rect(
+		int(p.X-p.Width/2),
+		int(p.Y),
+		int(p.X+p.Width/2),
+		int(p.Y+p.Height),
+	)
+}
+
+func (p *Projectile) Die() {
+	p.Dead = true
+}
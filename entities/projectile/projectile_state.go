@@ -0,0 +1,35 @@
+package projectile
+
+import (
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/base/physics"
+)
+
+// State is a copy of a projectile in flight. Projectiles come and go, so
+// unlike actor.State it restores into a new projectile.
+type State struct {
+	projectile Projectile
+	body       physics.Body
+	animator   base.AnimatorState
+}
+
+func (p *Projectile) Save() State {
+	return State{
+		projectile: *p,
+		body:       p.Body.Save(),
+		animator:   p.Animator.Save(),
+	}
+}
+
+func (s State) Restore() *Projectile {
+	p := s.projectile
+	body := s.body
+	p.Body = &body
+	animations := make(map[string]*base.Animation, len(s.animator.Animations))
+	for name, anim := range s.animator.Animations {
+		animations[name] = &anim
+	}
+	p.Animator = base.NewAnimator(animations)
+	p.CurrentAnimation = s.animator.CurrentAnimation
+	return &p
+}
@@ -1,18 +1,23 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image/color"
 	"log"
-	"os"
 
 	"github.com/gassyrdaulet/go-fighting-game/base"
-	"github.com/gassyrdaulet/go-fighting-game/base/physics"
+	"github.com/gassyrdaulet/go-fighting-game/base/navigation"
+	"github.com/gassyrdaulet/go-fighting-game/base/ui"
 	"github.com/gassyrdaulet/go-fighting-game/characters"
 	"github.com/gassyrdaulet/go-fighting-game/constants"
 	"github.com/gassyrdaulet/go-fighting-game/controllers"
-	"github.com/gassyrdaulet/go-fighting-game/entities/actor"
+	"github.com/gassyrdaulet/go-fighting-game/controllers/ai"
 	"github.com/gassyrdaulet/go-fighting-game/levels"
+	"github.com/gassyrdaulet/go-fighting-game/netplay"
+	"github.com/gassyrdaulet/go-fighting-game/render"
+	"github.com/gassyrdaulet/go-fighting-game/replay"
+	"github.com/gassyrdaulet/go-fighting-game/simulation"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 )
@@ -20,24 +25,37 @@ import (
 type Game struct {
 	state       	*base.StateMachine
 	introTimer  	int
-	world       	*physics.World
-	tileMap     	*base.TileMap
+	resultsTimer	int
+	sim         	*simulation.Simulation
 	camera      	*base.Camera
-	bg          	*base.Background
-	players     	[]*actor.Actor
+	bg          	*render.Background
+	tiles       	*render.TileMapRenderer
+	sprites     	map[string]render.SpriteSet
 	playersChars	map[string]*characters.Character
 	controllers 	[]base.Controller
+	slots       	*controllers.Slots
 	levelName   	string
 	input       	*Input
+	navigator   	ui.Navigator
+	nav         	ui.Nav
+	menu        	*ui.Container
 	full_screen 	bool
-	friendlyFire	bool
-}
-
-type Level struct {
-	World   *physics.World
-	TileMap *base.TileMap
-	BG      *base.Background
-	Players []*actor.Actor
+	rules       	simulation.Rules
+	charIDs     	[]string
+	teams       	[]int
+	aiPlayers   	int
+	aiProfiles  	[]*ai.Profile
+	slotProfiles	[]int
+	recorder    	*replay.Recorder
+	playback    	*replay.Replay
+	desync      	*replay.Desync
+	netConfig   	*netplay.Config
+	online      	bool
+	session     	*netplay.Session
+	stalled     	bool
+	controls    	controlsScreen
+	selection   	selectScreen
+	levelSelect 	levelSelectScreen
 }
 
 type Input struct {
@@ -64,9 +82,16 @@ const (
 	StateMainMenu base.State = "main_menu"
 	StatePlaying  base.State = "playing"
 	StatePaused   base.State = "paused"
+	StateControls base.State = "controls"
+	StateResults  base.State = "results"
+	StateSelect   base.State = "select"
+	StateLevels   base.State = "levels"
 )
 
 func (g *Game) Update() error {
+	g.slots.Update()
+	g.nav = g.navigator.Update(g.pauseHeld(), g.menuInputs()...)
+
 	if g.input.JustPressed(ebiten.KeyF11) {
 		g.setFullScreen(!g.full_screen)
 	}
@@ -84,30 +109,40 @@ func (g *Game) Update() error {
 
 	case StatePaused:
 		g.updatePause()
+
+	case StateControls:
+		g.updateControls()
+
+	case StateResults:
+		g.updateResults()
+
+	case StateSelect:
+		g.updateSelect()
+
+	case StateLevels:
+		g.updateMenu()
 	}
 
 	return nil
 }
 
-func (g *Game) updateMenu() {
-	if g.input.JustPressed(ebiten.Key1) {
-		g.levelName = "ai-arena"
-		g.state.ChangeState(StatePlaying)
-	}
-
-	if g.input.JustPressed(ebiten.KeyEscape) {
-		os.Exit(0)
+func (g *Game) slotProfile(slot int) *ai.Profile {
+	if slot >= len(g.slotProfiles) || len(g.aiProfiles) == 0 {
+		return nil
 	}
+	return g.aiProfiles[g.slotProfiles[slot]]
 }
 
 func (g *Game) updatePause() {
-	if g.input.JustPressed(ebiten.KeyEscape) {
-		g.state.ChangeState(StatePlaying)
+	if g.session != nil {
+		g.session.Poll()
 	}
 
-	if g.input.JustPressed(ebiten.KeyM) {
-		g.state.ChangeState(StateMainMenu)
+	if g.nav.Pause {
+		g.resume()
+		return
 	}
+	g.updateMenu()
 }
 
 func (g *Game) updateIntro() {
@@ -123,23 +158,73 @@ func (g *Game) updateIntro() {
 }
 
 func (g *Game) updateGame() {
-	for _, a := range g.players {
-		a.Update(g.world, g.players, g.friendlyFire)
+	if g.session != nil {
+		if !g.stepOnline() {
+			return
+		}
+	} else {
+		g.stepLocal()
 	}
 
-	playersPos := make([]base.PlayerPosition, 0, len(g.players))
-	for _, p := range g.players {
+	playersPos := make([]base.PlayerPosition, 0, len(g.sim.Players))
+	for _, p := range g.sim.Players {
 		playersPos = append(playersPos, p)
 	}
 
-	g.camera.UpdateFromPlayers(playersPos, g.world.Width, g.world.Height)
-	g.world.UpdateVirtualBounds(g.camera)
+	g.camera.UpdateFromPlayers(playersPos, g.sim.World.Width, g.sim.World.Height)
+	g.sim.World.UpdateVirtualBounds(g.camera)
+
+	if g.sim.Over && (g.session == nil || g.session.Settled()) {
+		g.state.ChangeState(StateResults)
+		return
+	}
 
-	if g.input.JustPressed(ebiten.KeyEscape) {
+	if g.playback != nil && g.sim.Tick >= g.playback.Ticks() {
+		g.state.ChangeState(StateMainMenu)
+		return
+	}
+
+	// An online match can't pause: the peer would be left waiting on
+	// inputs that never come.
+	if g.session == nil && g.nav.Pause {
 		g.state.ChangeState(StatePaused)
 	}
 }
 
+func (g *Game) stepLocal() {
+	inputs := make([]base.Input, len(g.controllers))
+	for i, ctrl := range g.controllers {
+		inputs[i] = ctrl.GetInput()
+	}
+	g.sim.Step(inputs)
+	if g.recorder != nil {
+		g.recorder.Record(inputs)
+	}
+	if g.playback != nil && g.desync == nil {
+		if g.desync = g.playback.Verify(g.sim); g.desync != nil {
+			log.Println(g.desync)
+		}
+	}
+}
+
+// stepOnline lets the session step the simulation, rolling back and
+// replaying frames on its own when the peer's inputs turn out different from
+// what it guessed. It returns false when there was no frame to step, either
+// because the peer is too far behind or because it is gone.
+func (g *Game) stepOnline() bool {
+	if !g.session.Connected() {
+		g.session.Poll()
+		return true
+	}
+	if g.session.Disconnected() {
+		log.Println("netplay: peer disconnected")
+		g.state.ChangeState(StateMainMenu)
+		return false
+	}
+	g.stalled = !g.session.AdvanceFrame(g.controllers[0].GetInput())
+	return !g.stalled
+}
+
 func (g *Game) Draw(screen *ebiten.Image) {
 	switch g.state.CurrentState {
 
@@ -148,15 +233,32 @@ func (g *Game) Draw(screen *ebiten.Image) {
 
 	case StateMainMenu:
 		g.drawControllersAmount(screen)
-		g.drawMainMenu(screen)
+		g.menu.Draw(screen)
 
 	case StatePlaying:
 		g.drawWorld(screen)
+		g.drawHUD(screen)
+		g.drawReplayProgress(screen)
+		g.drawOnlineStatus(screen)
 
 	case StatePaused:
 		g.drawWorld(screen)
 		g.drawPauseOverlay(screen)
-		g.drawPauseMenu(screen)
+		g.menu.Draw(screen)
+
+	case StateControls:
+		g.drawControls(screen)
+
+	case StateResults:
+		g.drawWorld(screen)
+		g.drawPauseOverlay(screen)
+		g.menu.Draw(screen)
+
+	case StateSelect:
+		g.drawSelect(screen)
+
+	case StateLevels:
+		g.drawLevelSelect(screen)
 	}
 
 	g.drawDebug(screen)
@@ -167,32 +269,61 @@ func (g *Game) drawWorld(screen *ebiten.Image) {
 		g.bg.Draw(screen, g.camera)
 	}
 
-	if g.tileMap != nil {
-		g.tileMap.Draw(screen, g.camera)
+	if g.tiles != nil {
+		g.tiles.Draw(screen, g.sim.TileMap, g.camera)
+		g.tiles.DrawPlatforms(screen, g.sim.Level.Platforms, g.sim.World.Platforms, g.camera)
 	}
 
-	for _, a := range g.players {
+	for _, a := range g.sim.Players {
 		sx, sy := g.camera.WorldToScreen(a.X, a.Y)
-		a.Draw(screen, sx, sy)
+		render.DrawActor(screen, a, g.sprites[a.Character.ID], sx, sy)
+	}
+
+	for _, p := range g.sim.Projectiles {
+		sx, sy := g.camera.WorldToScreen(p.X, p.Y)
+		render.DrawProjectile(screen, p, g.sprites[g.sim.Players[p.Owner].Character.ID], sx, sy)
 	}
 }
 
-func (g *Game) drawIntro(screen *ebiten.Image) {
+func (g *Game) drawReplayProgress(screen *ebiten.Image) {
+	if g.playback == nil {
+		return
+	}
 	ebitenutil.DebugPrintAt(
 		screen,
-		"https://github.com/gassyrdaulet/tiny-heroes",
-		constants.ScreenW/2-120,
-		constants.ScreenH/2,
+		fmt.Sprintf("REPLAY %d/%d", g.sim.Tick, g.playback.Ticks()),
+		constants.ScreenW-110,
+		10,
 	)
+	if g.desync != nil {
+		ebitenutil.DebugPrintAt(screen, g.desync.Error(), 10, 26)
+	}
 }
 
-func (g *Game) drawMainMenu(screen *ebiten.Image) {
-	text := "MAIN MENU\n\n[1] Start AI Battle\n[Esc] Exit"
+func (g *Game) drawOnlineStatus(screen *ebiten.Image) {
+	if g.session == nil {
+		return
+	}
+	if !g.session.Connected() || g.stalled {
+		ebitenutil.DebugPrintAt(screen, "Waiting for the other player...", constants.ScreenW/2-90, constants.ScreenH/2)
+		return
+	}
+	ebitenutil.DebugPrintAt(
+		screen,
+		fmt.Sprintf("ONLINE %d | rollbacks: %d", g.session.Frame(), g.session.Rollbacks),
+		constants.ScreenW-170,
+		10,
+	)
+	if g.session.DesyncFrame >= 0 {
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("desync at frame %d", g.session.DesyncFrame), 10, 26)
+	}
+}
 
+func (g *Game) drawIntro(screen *ebiten.Image) {
 	ebitenutil.DebugPrintAt(
 		screen,
-		text,
-		constants.ScreenW/2-100,
+		"https://github.com/gassyrdaulet/tiny-heroes",
+		constants.ScreenW/2-120,
 		constants.ScreenH/2,
 	)
 }
@@ -203,21 +334,19 @@ func (g *Game) drawPauseOverlay(screen *ebiten.Image) {
 	screen.DrawImage(overlay, nil)
 }
 
-func (g *Game) drawPauseMenu(screen *ebiten.Image) {
-	text := "PAUSED\n\n[Esc] Resume\n[M] Main Menu"
+func (g *Game) drawControllersAmount(screen *ebiten.Image) {
+	text := fmt.Sprintf("Controllers: %d", g.slots.Connected())
+	for slot := 0; slot < g.slots.Len(); slot++ {
+		device := "keyboard"
+		if pad := g.slots.Gamepad(slot); pad != nil {
+			device = ebiten.GamepadName(pad.ID)
+		}
+		text += fmt.Sprintf("\nP%d: %s", slot+1, device)
+	}
 
 	ebitenutil.DebugPrintAt(
 		screen,
 		text,
-		constants.ScreenW/2-80,
-		constants.ScreenH/2,
-	)
-}
-
-func (g *Game) drawControllersAmount(screen *ebiten.Image) {
-	ebitenutil.DebugPrintAt(
-		screen,
-		fmt.Sprintf("Controllers: %d", len(g.controllers)),
 		10,
 		10,
 	)
@@ -236,12 +365,10 @@ func (g *Game) drawDebug(screen *ebiten.Image) {
 	ebitenutil.DebugPrintAt(
 		screen,
 		fmt.Sprintf(
-			"FPS: %.0f | cam(%.0f, %.0f) | p1 alive: %t | p2 alive: %t",
+			"FPS: %.0f | cam(%.0f, %.0f)",
 			ebiten.ActualFPS(),
 			g.camera.X,
 			g.camera.Y,
-			!g.players[0].Dead,
-			!g.players[1].Dead,
 		),
 		10,
 		constants.ScreenH-20,
@@ -257,7 +384,22 @@ func NewGame() *Game {
 		state:       base.NewStateMachine(StateIntro),
 		input:       NewInput(),
 		full_screen: false,
+		slotProfiles: make([]int, 2),
+		rules:       defaultRules,
+	}
+	aiProfiles, err := ai.LoadProfiles("characters/ai_profiles.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+	g.aiProfiles = aiProfiles
+	controls, err := controllers.LoadControls(constants.ControlsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, c := range controls.Conflicts() {
+		log.Println("controls:", c)
 	}
+	g.slots = controllers.NewSlots(controls, constants.GamepadDeadzone)
 	g.state.OnChange = g.onStateChange
 	return g
 }
@@ -269,13 +411,27 @@ func (g *Game) onStateChange(prev, current base.State) {
 
 	case StateMainMenu:
 		g.mainMenu()
+		g.buildMainMenu()
 
 	case StatePlaying:
-		if g.world == nil {
-			g.initializeNewGame(g.levelName, true)
+		if g.sim == nil {
+			g.initializeNewGame(g.levelName)
 		}
 
 	case StatePaused:
+		g.buildPauseMenu()
+
+	case StateControls:
+		g.initControls()
+
+	case StateResults:
+		g.buildResults()
+
+	case StateSelect:
+		g.initSelect()
+
+	case StateLevels:
+		g.initLevelSelect()
 	}
 }
 
@@ -283,29 +439,27 @@ func (g *Game) initIntro() {
 	g.introTimer = 0
 }
 
-func (g *Game) initializeNewGame(initialLevelName string, friendlyFire bool) {
-	g.controllers = []base.Controller{
-		controllers.NewKeyboardController(
-			ebiten.KeyLeft,
-			ebiten.KeyRight,
-			ebiten.KeyUp,
-			ebiten.KeyDown,
-			ebiten.KeySpace,
-		),
-		controllers.NewKeyboardController(
-			ebiten.KeyA,
-			ebiten.KeyD,
-			ebiten.KeyW,
-			ebiten.KeyS,
-			ebiten.KeyF,
-		),
+func (g *Game) initializeNewGame(initialLevelName string) {
+	g.controllers = make([]base.Controller, g.slots.Len())
+	for i := range g.controllers {
+		if g.isBot(i) {
+			g.controllers[i] = ai.NewController(g.slotProfile(i), uint64(i+1))
+		} else {
+			g.controllers[i] = g.slots.Slot(i)
+		}
 	}
-	playersChars, err := characters.LoadCharacters("characters/players.json")
-	if err != nil {
+	if g.online {
+		g.controllers = []base.Controller{g.slots.Slot(0)}
+	}
+	if g.playback != nil {
+		g.controllers = make([]base.Controller, len(g.playback.Characters))
+		for i := range g.controllers {
+			g.controllers[i] = controllers.NewReplayController(g.playback, i)
+		}
+	}
+	if err := g.loadCharacters(); err != nil {
 		panic(err)
 	}
-	g.friendlyFire = friendlyFire
-	g.playersChars = playersChars
 	g.camera = &base.Camera{
 		Width:  constants.ScreenW,
 		Height: constants.ScreenH,
@@ -313,51 +467,148 @@ func (g *Game) initializeNewGame(initialLevelName string, friendlyFire bool) {
 	g.loadLevel(initialLevelName)
 }
 
+// isBot tells whether the slot is played by the AI in this match.
+func (g *Game) isBot(slot int) bool {
+	return slot >= g.slots.Len()-g.aiPlayers
+}
+
+// loadCharacters reads the characters and their sprites, unless they are
+// already loaded for this match.
+func (g *Game) loadCharacters() error {
+	if g.playersChars != nil {
+		return nil
+	}
+	playersChars, err := characters.LoadCharacters("characters/players.json")
+	if err != nil {
+		return err
+	}
+	sprites, err := render.LoadSprites(playersChars)
+	if err != nil {
+		return err
+	}
+	g.playersChars = playersChars
+	g.sprites = sprites
+	return nil
+}
+
 func (g *Game) loadLevel(levelName string) {
-	g.players = nil
-	g.tileMap = nil
-	if g.world != nil {
-		g.world.Clear()
+	if g.sim != nil {
+		g.sim.World.Clear()
+	}
+
+	charIDs := levels.DefaultCharacterIDs(len(g.controllers), g.playersChars)
+	if len(g.charIDs) == len(g.controllers) {
+		charIDs = g.charIDs
+	}
+	teams, rules := g.teams, g.rules
+	if g.playback != nil {
+		charIDs = g.playback.Characters
+		teams, rules = g.playback.Teams, g.playback.Rules
+	}
+	if g.online {
+		// The peer doesn't know our settings, so both sides play the
+		// defaults to stay in sync.
+		charIDs = levels.DefaultCharacterIDs(netplay.Players, g.playersChars)
+		teams, rules = nil, defaultRules
 	}
 
-	level, err := levels.LoadLevel(levelName); if err != nil {
+	sim, err := simulation.New(levelName, g.playersChars, charIDs, teams, rules); if err != nil {
 		log.Fatal(err)
 	}
+	g.sim = sim
 
-	g.tileMap = levels.BuildTileMapFromLines(level.TileMap)
+	g.recorder = nil
+	if g.playback == nil && !g.online {
+		g.recorder = replay.NewRecorder(sim)
+	}
 
-	g.bg = levels.BuildBackground(level.Background, float64(g.tileMap.Height * constants.TileSize))
+	if g.online {
+		session, err := netplay.NewSession(*g.netConfig, sim); if err != nil {
+			log.Fatal(err)
+		}
+		g.session = session
+		g.stalled = false
+	}
+
+	tiles, err := render.NewTileMapRenderer(sim.Level.TileMap.Tileset); if err != nil {
+		log.Fatal(err)
+	}
+	g.tiles = tiles
 
-	g.world = physics.NewWorld(g.tileMap)
+	g.bg = render.BuildBackground(sim.Level.Background, float64(sim.TileMap.Height * constants.TileSize))
 
-	g.players = levels.SpawnPlayers(g.controllers, g.playersChars, level.Spawns)
+	for i, ctrl := range g.controllers {
+		if bot, ok := ctrl.(*ai.Controller); ok && i < len(sim.Players) {
+			bot.Attach(sim.Players[i], sim.Players, sim.World)
+			bot.UseNavigation(navigation.Build(sim.TileMap, sim.Players[i].Character))
+		}
+	}
 
 	g.levelName = levelName
 }
 
 func (g *Game) mainMenu() {
+	g.saveReplay()
+	g.recorder = nil
+	g.playback = nil
+	g.desync = nil
+	if g.session != nil {
+		g.session.Close()
+		g.session = nil
+	}
 	g.playersChars = nil
-	g.players = nil
-	g.world = nil
-	g.tileMap = nil
+	g.sprites = nil
+	g.charIDs = nil
+	g.teams = nil
+	g.sim = nil
+	g.tiles = nil
 	g.bg = nil
 	g.camera = nil
 	g.levelName = ""
 }
 
+func (g *Game) saveReplay() {
+	if g.recorder == nil || g.recorder.Replay().Ticks() == 0 {
+		return
+	}
+	path := constants.ReplaysDirectory + constants.LastReplayName
+	if err := replay.Save(path, g.recorder.Replay()); err != nil {
+		log.Println(err)
+	}
+}
+
 func (g *Game) setFullScreen(value bool) {
 	g.full_screen = value
 	ebiten.SetFullscreen(value)
 }
 
 func main() {
-	ebiten.SetTPS(60)
+	netLocal := flag.String("net-local", "", "UDP address to listen on for an online match, e.g. :7000")
+	netRemote := flag.String("net-remote", "", "UDP address of the other player, e.g. 127.0.0.1:7001")
+	netPlayer := flag.Int("net-player", 0, "player slot controlled on this machine, 0 or 1")
+	netDelay := flag.Int("net-delay", 2, "input delay in frames")
+	netLatency := flag.Duration("net-latency", 0, "simulated one-way latency, e.g. 60ms")
+	netLoss := flag.Float64("net-loss", 0, "simulated packet loss from 0 to 1")
+	flag.Parse()
+
+	ebiten.SetTPS(constants.TicksPerSecond)
 	ebiten.SetWindowSize(constants.WindowW, constants.WindowH)
 	ebiten.SetWindowTitle("Tiny Heroes")
 
 	game := NewGame()
 	game.setFullScreen(false)
 
+	if *netLocal != "" && *netRemote != "" {
+		game.netConfig = &netplay.Config{
+			LocalAddr:   *netLocal,
+			RemoteAddr:  *netRemote,
+			LocalPlayer: *netPlayer,
+			InputDelay:  *netDelay,
+			Latency:     *netLatency,
+			Loss:        *netLoss,
+		}
+	}
+
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
 	}
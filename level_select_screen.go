@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gassyrdaulet/go-fighting-game/base/ui"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+	"github.com/gassyrdaulet/go-fighting-game/levels"
+	"github.com/gassyrdaulet/go-fighting-game/render"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	thumbnailW = 200
+	thumbnailH = 140
+)
+
+// levelSelectScreen lists every level in the levels directory with a small
+// picture of it. The thumbnails are drawn once, when the screen opens.
+type levelSelectScreen struct {
+	levels     []levels.Info
+	thumbnails []*ebiten.Image
+	list       *ui.List
+	message    string
+}
+
+// levelRows is how many levels the list shows before it scrolls.
+const levelRows = 14
+
+func (g *Game) initLevelSelect() {
+	s := levelSelectScreen{}
+
+	list, err := levels.ListLevels()
+	if err != nil {
+		log.Println(err)
+		s.message = "Could not read the levels"
+	}
+	s.levels = list
+	s.thumbnails = make([]*ebiten.Image, len(list))
+	s.list = ui.NewList(make([]string, len(list)), g.playLevel)
+	s.list.Rows = levelRows
+	for i, l := range list {
+		s.list.Items[i] = l.Title()
+		if l.ID == g.levelName {
+			s.list.Selected = i
+		}
+		thumb, err := render.Thumbnail(l.Level.TileMap, thumbnailW, thumbnailH)
+		if err != nil {
+			log.Printf("level %s: %v", l.ID, err)
+			continue
+		}
+		s.thumbnails[i] = thumb
+	}
+
+	g.levelSelect = s
+
+	menu := ui.NewContainer(ui.Vertical, menuSpacing, ui.NewLabel("LEVEL SELECT\n"), s.list)
+	if len(list) == 0 {
+		menu.Add(ui.NewLabel("No levels in " + constants.LevelsDirectory))
+	}
+	if s.message != "" {
+		menu.Add(ui.NewLabel(s.message))
+	}
+	menu.OnBack = func() { g.state.ChangeState(StateMainMenu) }
+	g.openMenu(menu, 20, 10)
+}
+
+func (g *Game) playLevel(i int) {
+	g.levelName = g.levelSelect.levels[i].ID
+	if g.aiPlayers < g.slots.Len() {
+		g.state.ChangeState(StateSelect)
+	} else {
+		g.state.ChangeState(StatePlaying)
+	}
+}
+
+func (g *Game) drawLevelSelect(screen *ebiten.Image) {
+	s := &g.levelSelect
+
+	g.menu.Draw(screen)
+
+	if len(s.levels) > 0 {
+		l := s.levels[s.list.Selected]
+		x := constants.ScreenW - thumbnailW - 20
+		if thumb := s.thumbnails[s.list.Selected]; thumb != nil {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(x), 40)
+			screen.DrawImage(thumb, op)
+		}
+
+		author := l.Level.Author
+		if author == "" {
+			author = "unknown"
+		}
+		ebitenutil.DebugPrintAt(
+			screen,
+			fmt.Sprintf("%s\nby %s\n%d players", l.Title(), author, l.PlayerCount()),
+			x,
+			40+thumbnailH+10,
+		)
+	}
+
+	ebitenutil.DebugPrintAt(screen, "[Up/Down] Select  [Attack/Enter] Play  [Block/Esc] Back", 20, constants.ScreenH-30)
+}
@@ -2,22 +2,55 @@ package levels
 
 import (
 	"encoding/json"
+	"fmt"
+	"log"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/gassyrdaulet/go-fighting-game/base"
 	c "github.com/gassyrdaulet/go-fighting-game/characters"
 	"github.com/gassyrdaulet/go-fighting-game/constants"
 	"github.com/gassyrdaulet/go-fighting-game/entities/actor"
 	"github.com/gassyrdaulet/go-fighting-game/levels/tileset"
-	"github.com/gassyrdaulet/go-fighting-game/utils"
 )
 
+// LevelData is a level file. Name, Author and Players only describe the level
+// on the level select screen and may be left out, and so may Platforms.
 type LevelData struct {
+	Name       string          `json:"name,omitempty"`
+	Author     string          `json:"author,omitempty"`
+	Players    int             `json:"players,omitempty"`
 	TileMap    TileMapData     `json:"tilemap"`
 	Background []BackgroundDef `json:"background"`
 	Spawns     []SpawnPoint    `json:"spawns"`
+	Platforms  []PlatformData  `json:"platforms,omitempty"`
+}
+
+// Info is a level found in the levels directory, ID being the name it is
+// loaded by.
+type Info struct {
+	ID    string
+	Level *LevelData
+}
+
+// Title is the name of the level, its ID when it has none.
+func (i Info) Title() string {
+	if i.Level.Name != "" {
+		return i.Level.Name
+	}
+	return i.ID
+}
+
+// PlayerCount is how many players the level is made for, one per spawn point
+// unless it says otherwise.
+func (i Info) PlayerCount() int {
+	if i.Level.Players > 0 {
+		return i.Level.Players
+	}
+	return len(i.Level.Spawns)
 }
 
 type TileMapData struct {
@@ -34,9 +67,12 @@ type BackgroundDef struct {
 	StretchY bool    `json:"stretchY"`
 }
 
+// SpawnPoint is where a player starts. Players spawning with the same Team
+// fight together; without one every player is on a team of its own.
 type SpawnPoint struct {
-	X float64 `json:"x"`
-	Y float64 `json:"y"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Team int     `json:"team,omitempty"`
 }
 
 func LoadLevel(levelName string) (*LevelData, error) {
@@ -49,18 +85,54 @@ func LoadLevel(levelName string) (*LevelData, error) {
 	if err := json.Unmarshal(data, &level); err != nil {
 		return nil, err
 	}
+	if err := validatePlatforms(level.Platforms); err != nil {
+		return nil, err
+	}
+	if _, err := BuildTileMapFromLines(level.TileMap); err != nil {
+		return nil, err
+	}
 
 	return &level, nil
 }
 
-func BuildTileMapFromLines(data TileMapData) *base.TileMap {
+// ListLevels loads every level in the levels directory, sorted by ID. Levels
+// that fail to load are logged and left out.
+func ListLevels() ([]Info, error) {
+	entries, err := os.ReadDir(constants.LevelsDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Info
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		id := strings.TrimSuffix(e.Name(), ".json")
+		level, err := LoadLevel(id)
+		if err != nil {
+			log.Printf("level %s: %v", id, err)
+			continue
+		}
+		result = append(result, Info{ID: id, Level: level})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+func BuildTileMapFromLines(data TileMapData) (*base.TileMap, error) {
 	tileMap := base.NewTileMap(
 		data.Width,
 		data.Height,
 		constants.TileSize,
 	)
 
-	tileset.LoadTileSetFromJSON(tileMap, data.Tileset)
+	if err := tileset.LoadTileSetFromJSON(tileMap, data.Tileset); err != nil {
+		return nil, err
+	}
 
 	symbolToID := map[rune]int{
 		'=': 2,
@@ -81,6 +153,7 @@ func BuildTileMapFromLines(data TileMapData) *base.TileMap {
 		'-': 37,
 		'»': 38,
 		'~': 21,
+		'^': 39,
 		' ': 0,
 	}
 
@@ -100,60 +173,80 @@ func BuildTileMapFromLines(data TileMapData) *base.TileMap {
 		}
 	}
 
-	return tileMap
+	return tileMap, nil
 }
 
-func BuildBackground(defs []BackgroundDef, groundY float64) *base.Background {
-    layers := []*base.BackgroundLayer{}
+// DefaultCharacterIDs hands out the characters in ID order, wrapping around
+// when there are more players than characters.
+func DefaultCharacterIDs(count int, characters map[string]*c.Character) []string {
+	if len(characters) == 0 {
+		return nil
+	}
+
+	charIDs := make([]string, 0, len(characters))
+	for id := range characters {
+		charIDs = append(charIDs, id)
+	}
+
+    sort.Strings(charIDs)
 
-    for _, bg := range defs {
-        layers = append(layers, &base.BackgroundLayer{
-            Image:    utils.MustLoad(bg.Image),
-            ScrollX:  bg.ScrollX,
-            ScrollY:  bg.ScrollY,
-            StretchY: bg.StretchY,
-        })
+    result := make([]string, count)
+    for i := range result {
+        result[i] = charIDs[i % len(charIDs)]
     }
 
-    return &base.Background{Layers: layers, BaseY: groundY}
+    return result
+}
+
+// Spawn is where player i starts, the first spawn point for players past the
+// last one.
+func Spawn(spawns []SpawnPoint, i int) SpawnPoint {
+	if i < len(spawns) {
+		return spawns[i]
+	}
+	return spawns[0]
 }
 
+// SpawnPlayers places a player for every character ID. teams[i], when given
+// and not 0, overrides the team of the spawn point player i starts at.
 func SpawnPlayers(
-    controllers []base.Controller,
+    charIDs []string,
     characters map[string]*c.Character,
     spawns []SpawnPoint,
-) []*actor.Actor {
-	if len(controllers) == 0 || len(characters) == 0 {
-		return nil
+    teams []int,
+) ([]*actor.Actor, error) {
+	if len(charIDs) == 0 {
+		return nil, nil
 	}
-
-	charIDs := make([]string, 0, len(characters))
-	for id := range characters {
-		charIDs = append(charIDs, id)
+	if len(spawns) == 0 {
+		return nil, fmt.Errorf("level has no spawn points")
 	}
 
-    sort.Strings(charIDs)
+    players := make([]*actor.Actor, 0, len(charIDs))
 
-    players := make([]*actor.Actor, 0, len(controllers))
+    for i, charID := range charIDs {
+        spawn := Spawn(spawns, i)
 
-    for i, ctrl := range controllers {
-        spawn := spawns[0]
-        if i < len(spawns) {
-            spawn = spawns[i]
+        char, ok := characters[charID]
+        if !ok {
+            return nil, fmt.Errorf("unknown character: %s", charID)
         }
 
-        charID := charIDs[i % len(charIDs)]
-
-        players = append(players,
-            actor.NewActor(
-                spawn.X,
-                spawn.Y,
-                ctrl,
-                1,
-                characters[charID],
-            ),
+        a := actor.NewActor(
+            spawn.X,
+            spawn.Y,
+            1,
+            char,
         )
+        a.Team = i + 1
+        if spawn.Team != 0 {
+            a.Team = spawn.Team
+        }
+        if i < len(teams) && teams[i] != 0 {
+            a.Team = teams[i]
+        }
+        players = append(players, a)
     }
 
-    return players
-}
\ No newline at end of file
+    return players, nil
+}
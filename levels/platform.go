@@ -0,0 +1,77 @@
+package levels
+
+import (
+	"fmt"
+
+	"github.com/gassyrdaulet/go-fighting-game/base/physics"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+)
+
+// PlatformData is a moving platform. Its waypoints are where its top middle
+// passes, like a spawn point, and it starts on the first one. Width and
+// Height are in tiles, Height being one when left out, and Tile is the
+// tileset tile it is drawn with. Mode is "pingpong", the default, or "loop";
+// Wait is how many ticks it stops at every waypoint. A platform that isn't
+// Solid can be jumped up through and dropped down through.
+type PlatformData struct {
+	Tile      int        `json:"tile"`
+	Width     int        `json:"width"`
+	Height    int        `json:"height,omitempty"`
+	Solid     bool       `json:"solid,omitempty"`
+	Speed     float64    `json:"speed"`
+	Mode      string     `json:"mode,omitempty"`
+	Wait      int        `json:"wait,omitempty"`
+	Waypoints []Waypoint `json:"waypoints"`
+}
+
+type Waypoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+var pathModes = map[string]physics.PathMode{
+	"":         physics.PingPong,
+	"pingpong": physics.PingPong,
+	"loop":     physics.Loop,
+}
+
+func validatePlatforms(platforms []PlatformData) error {
+	for i, p := range platforms {
+		if p.Width <= 0 {
+			return fmt.Errorf("platform %d: width must be positive", i)
+		}
+		if len(p.Waypoints) == 0 {
+			return fmt.Errorf("platform %d: no waypoints", i)
+		}
+		if len(p.Waypoints) > 1 && p.Speed <= 0 {
+			return fmt.Errorf("platform %d: speed must be positive", i)
+		}
+		if _, ok := pathModes[p.Mode]; !ok {
+			return fmt.Errorf("platform %d: unknown mode %s", i, p.Mode)
+		}
+	}
+	return nil
+}
+
+// BuildPlatforms puts every platform of a level on its first waypoint.
+func BuildPlatforms(platforms []PlatformData) []*physics.Platform {
+	result := make([]*physics.Platform, len(platforms))
+	for i, data := range platforms {
+		height := max(data.Height, 1)
+		path := make([]physics.Point, len(data.Waypoints))
+		for j, w := range data.Waypoints {
+			path[j] = physics.Point{X: w.X, Y: w.Y}
+		}
+		p := physics.NewPlatform(
+			float64(data.Width*constants.TileSize),
+			float64(height*constants.TileSize),
+			path,
+			data.Speed,
+			pathModes[data.Mode],
+		)
+		p.Solid = data.Solid
+		p.Wait = data.Wait
+		result[i] = p
+	}
+	return result
+}
@@ -2,11 +2,11 @@ package tileset
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 
 	"github.com/gassyrdaulet/go-fighting-game/base"
 	"github.com/gassyrdaulet/go-fighting-game/constants"
-	"github.com/gassyrdaulet/go-fighting-game/utils"
 )
 
 type TileSetJSON struct {
@@ -19,42 +19,85 @@ const (
     TileSolid    TileType = "solid"
     TilePlatform TileType = "platform"
     TileDecor    TileType = "decor"
+    TileHazard   TileType = "hazard"
 )
 
+// Shapes a solid tile can take besides a full block. The 45 degree slopes
+// fill one tile; the 22.5 degree ones take two, a low and a high half.
+var slopeShapes = map[string]base.TileSlope{
+    "slope-up":        {Left: 0, Right: 1},
+    "slope-down":      {Left: 1, Right: 0},
+    "slope-up-low":    {Left: 0, Right: 0.5},
+    "slope-up-high":   {Left: 0.5, Right: 1},
+    "slope-down-high": {Left: 1, Right: 0.5},
+    "slope-down-low":  {Left: 0.5, Right: 0},
+}
+
+// TileDefJSON is one tile of a tileset. Shape only means something on solid
+// tiles, and the damage, knockback and kill fields only on hazard tiles; see
+// base.TileHazard.
 type TileDefJSON struct {
-	ID    int       	`json:"id"`
-	Type  TileType      `json:"type"`
-	Image string    	`json:"image"`
+	ID         int      	`json:"id"`
+	Type       TileType 	`json:"type"`
+	Image      string   	`json:"image"`
+	Shape      string   	`json:"shape,omitempty"`
+	Damage     int      	`json:"damage,omitempty"`
+	KnockbackX float64  	`json:"knockbackX,omitempty"`
+	KnockbackY float64  	`json:"knockbackY,omitempty"`
+	Kill       bool     	`json:"kill,omitempty"`
 }
 
-func LoadTileSetFromJSON(
-    tileMap *base.TileMap,
-    tilesetName string,
-) error {
+func LoadTileSet(tilesetName string) (*TileSetJSON, error) {
     data, err := os.ReadFile(constants.TilesetDirectory + tilesetName + ".json")
     if err != nil {
-        return err
+        return nil, err
     }
 
     var tileset TileSetJSON
     if err := json.Unmarshal(data, &tileset); err != nil {
+        return nil, err
+    }
+
+    return &tileset, nil
+}
+
+func LoadTileSetFromJSON(
+    tileMap *base.TileMap,
+    tilesetName string,
+) error {
+    tileset, err := LoadTileSet(tilesetName)
+    if err != nil {
         return err
     }
 
     for _, tile := range tileset.Tiles {
-        img := utils.MustLoad(tile.Image)
-
         var collision base.TileType
         switch tile.Type {
         case TileSolid:
             collision = base.Solid
+            if tile.Shape != "" && tile.Shape != "full" {
+                slope, ok := slopeShapes[tile.Shape]
+                if !ok {
+                    return fmt.Errorf("tileset %s: tile %d: unknown shape %s", tilesetName, tile.ID, tile.Shape)
+                }
+                tileMap.AddSlope(tile.ID, slope)
+                continue
+            }
         case TilePlatform:
             collision = base.Platform
+        case TileHazard:
+            tileMap.AddHazard(tile.ID, base.TileHazard{
+                Damage:     tile.Damage,
+                KnockbackX: tile.KnockbackX,
+                KnockbackY: tile.KnockbackY,
+                Kill:       tile.Kill,
+            })
+            continue
         default:
             collision = base.Empty
         }
 
-        tileMap.AddTileType(tile.ID, collision, img)
+        tileMap.AddTileType(tile.ID, collision)
     }
 
     return nil
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gassyrdaulet/go-fighting-game/base/ui"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+	"github.com/gassyrdaulet/go-fighting-game/simulation"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// maxStocks and maxRounds are as high as the main menu goes.
+const (
+	maxStocks = 5
+	maxRounds = 3
+)
+
+var defaultRules = simulation.Rules{
+	Stocks:      3,
+	Rounds:      1,
+	TimeLimit:   3 * 60 * constants.TicksPerSecond,
+	SuddenDeath: true,
+}
+
+// timeLimits are the time limits the main menu offers, in minutes. 0 is no
+// limit.
+var timeLimits = []int{0, 1, 2, 3, 5}
+
+func timeLimitTicks(i int) int {
+	return timeLimits[i] * 60 * constants.TicksPerSecond
+}
+
+// rulesMenu are the widgets of the main menu that set the rules of the next
+// match.
+func (g *Game) rulesMenu() []ui.Widget {
+	timeLimit := 0
+	for i := range timeLimits {
+		if timeLimitTicks(i) == g.rules.TimeLimit {
+			timeLimit = i
+		}
+	}
+
+	stocks := ui.NewSlider("Stocks", g.rules.Stocks, 1, maxStocks, func(v int) {
+		g.rules.Stocks = v
+	})
+	rounds := ui.NewSlider("Rounds to win", max(g.rules.Rounds, 1), 1, maxRounds, func(v int) {
+		g.rules.Rounds = v
+	})
+	clock := ui.NewSlider("Time limit", timeLimit, 0, len(timeLimits)-1, func(v int) {
+		g.rules.TimeLimit = timeLimitTicks(v)
+	})
+	clock.Format = func(v int) string {
+		if v == 0 {
+			return "none"
+		}
+		return formatTicks(timeLimitTicks(v))
+	}
+
+	return []ui.Widget{
+		stocks,
+		rounds,
+		clock,
+		ui.NewToggle("Sudden death", g.rules.SuddenDeath, func(v bool) {
+			g.rules.SuddenDeath = v
+		}),
+		ui.NewToggle("Friendly fire", g.rules.FriendlyFire, func(v bool) {
+			g.rules.FriendlyFire = v
+		}),
+		ui.NewToggle("Body collision", g.rules.BodyCollision, func(v bool) {
+			g.rules.BodyCollision = v
+		}),
+	}
+}
+
+// drawHUD shows the stocks left of every player, the rounds they have won
+// and the time left, and counts down to the start of a round.
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	rounds := g.sim.Rules.Rounds > 1
+
+	text := ""
+	for i, a := range g.sim.Players {
+		text += fmt.Sprintf("P%d x%d", i+1, a.Stocks)
+		if rounds {
+			text += fmt.Sprintf(" (%d/%d)", a.RoundsWon, g.sim.Rules.Rounds)
+		}
+		text += "  "
+	}
+	if g.sim.SuddenDeath {
+		text += "SUDDEN DEATH"
+	} else if g.sim.Rules.TimeLimit > 0 {
+		text += formatTicks(max(g.sim.Rules.TimeLimit-g.sim.Clock, 0))
+	}
+	ebitenutil.DebugPrintAt(screen, text, 10, 10)
+
+	if g.sim.Countdown > 0 {
+		seconds := (g.sim.Countdown + constants.TicksPerSecond - 1) / constants.TicksPerSecond
+		ebitenutil.DebugPrintAt(
+			screen,
+			fmt.Sprintf("ROUND %d\n\n   %d", g.sim.Round, seconds),
+			constants.ScreenW/2-25,
+			constants.ScreenH/2-40,
+		)
+	} else if rounds && g.sim.Clock < constants.TicksPerSecond {
+		ebitenutil.DebugPrintAt(screen, "FIGHT!", constants.ScreenW/2-20, constants.ScreenH/2-40)
+	}
+}
+
+// resultsDelay keeps the results up for a moment before they take input, so
+// the attacks still being mashed when the match ends don't skip them.
+const resultsDelay = constants.TicksPerSecond
+
+func (g *Game) buildResults() {
+	g.resultsTimer = 0
+
+	mainMenu := func() { g.state.ChangeState(StateMainMenu) }
+	menu := ui.NewContainer(ui.Vertical, menuSpacing,
+		ui.NewLabel(g.resultsText()),
+		ui.NewButton("Main Menu", mainMenu),
+	)
+	menu.OnBack = mainMenu
+
+	g.openMenu(menu, constants.ScreenW/2-110, constants.ScreenH/2-60)
+}
+
+func (g *Game) updateResults() {
+	if g.session != nil {
+		g.session.Poll()
+	}
+
+	if g.resultsTimer < resultsDelay {
+		g.resultsTimer++
+		return
+	}
+	g.updateMenu()
+}
+
+func (g *Game) resultsText() string {
+	text := "DRAW\n\n"
+	if g.sim.Winner != 0 {
+		text = fmt.Sprintf("TEAM %d WINS\n\n", g.sim.Winner)
+		if winners := g.teamPlayers(g.sim.Winner); len(winners) == 1 {
+			text = fmt.Sprintf("P%d WINS\n\n", winners[0]+1)
+		}
+	}
+
+	text += "         DAMAGE  KOS  FALLS  ROUNDS\n"
+	for i, a := range g.sim.Players {
+		text += fmt.Sprintf("P%d %-5s %6d %4d %6d %7d\n", i+1, a.Character.Name, a.DamageDealt, a.KOs, a.Falls, a.RoundsWon)
+	}
+	return text
+}
+
+// teamPlayers are the indexes of the players on team.
+func (g *Game) teamPlayers(team int) []int {
+	var players []int
+	for i, a := range g.sim.Players {
+		if a.Team == team {
+			players = append(players, i)
+		}
+	}
+	return players
+}
+
+// formatTicks shows a number of ticks as minutes and seconds, rounding up so
+// the clock reads 0:00 only once time is up.
+func formatTicks(ticks int) string {
+	seconds := (ticks + constants.TicksPerSecond - 1) / constants.TicksPerSecond
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/base/ui"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+	"github.com/gassyrdaulet/go-fighting-game/controllers/ai"
+	"github.com/gassyrdaulet/go-fighting-game/replay"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const menuSpacing = 1
+
+// menuInputs are the inputs every menu listens to: each player slot, whatever
+// device holds it, and the arrows, Enter and Esc on the keyboard, which work
+// even while a gamepad holds the first slot.
+func (g *Game) menuInputs() []base.Input {
+	inputs := make([]base.Input, 0, g.slots.Len()+1)
+	for slot := range g.slots.Len() {
+		inputs = append(inputs, g.slots.Slot(slot).GetInput())
+	}
+	return append(inputs, base.Input{
+		Left:   ebiten.IsKeyPressed(ebiten.KeyLeft),
+		Right:  ebiten.IsKeyPressed(ebiten.KeyRight),
+		Up:     ebiten.IsKeyPressed(ebiten.KeyUp),
+		Down:   ebiten.IsKeyPressed(ebiten.KeyDown),
+		Attack: ebiten.IsKeyPressed(ebiten.KeyEnter),
+		Block:  ebiten.IsKeyPressed(ebiten.KeyEscape),
+	})
+}
+
+// pauseHeld reports whether Esc or Start on any gamepad is held.
+func (g *Game) pauseHeld() bool {
+	return ebiten.IsKeyPressed(ebiten.KeyEscape) || g.slots.StartHeld()
+}
+
+// openMenu makes menu the one that gets the navigation, with the focus on its
+// first widget.
+func (g *Game) openMenu(menu *ui.Container, x, y int) {
+	menu.Wrap = true
+	menu.SetPosition(x, y)
+	menu.SetFocused(true)
+	g.menu = menu
+}
+
+func (g *Game) updateMenu() {
+	g.menu.Handle(g.nav)
+	g.menu.Update()
+}
+
+func (g *Game) buildMainMenu() {
+	menu := ui.NewContainer(ui.Vertical, menuSpacing,
+		ui.NewLabel("MAIN MENU\n"),
+		ui.NewButton("Start AI Battle", func() { g.startLocal(1) }),
+		ui.NewButton("Watch AI vs AI", func() { g.startLocal(2) }),
+	)
+
+	for slot := range g.slotProfiles {
+		bot := ui.NewSlider(fmt.Sprintf("P%d bot", slot+1), g.slotProfiles[slot], 0, len(g.aiProfiles)-1, func(v int) {
+			g.slotProfiles[slot] = v
+		})
+		bot.Format = func(int) string {
+			if p := g.slotProfile(slot); p != nil {
+				return p.Name
+			}
+			return ai.DefaultProfile.Name
+		}
+		menu.Add(bot)
+	}
+
+	menu.Add(ui.NewButton("Watch last replay", g.watchLastReplay))
+	if g.netConfig != nil {
+		menu.Add(ui.NewButton("Start online match", g.startOnline))
+	}
+
+	menu.Add(g.rulesMenu()...)
+	menu.Add(
+		ui.NewButton("Controls", func() { g.state.ChangeState(StateControls) }),
+		ui.NewButton("Exit", func() { os.Exit(0) }),
+	)
+
+	g.openMenu(menu, constants.ScreenW/2-100, 10)
+}
+
+func (g *Game) startLocal(aiPlayers int) {
+	g.aiPlayers = aiPlayers
+	g.playback = nil
+	g.online = false
+	g.state.ChangeState(StateLevels)
+}
+
+func (g *Game) watchLastReplay() {
+	rp, err := replay.Load(constants.ReplaysDirectory + constants.LastReplayName)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	g.levelName = rp.Level
+	g.playback = rp
+	g.online = false
+	g.state.ChangeState(StatePlaying)
+}
+
+func (g *Game) startOnline() {
+	g.levelName = constants.DefaultLevel
+	g.aiPlayers = 0
+	g.playback = nil
+	g.online = true
+	g.state.ChangeState(StatePlaying)
+}
+
+func (g *Game) buildPauseMenu() {
+	menu := ui.NewContainer(ui.Vertical, menuSpacing,
+		ui.NewLabel("PAUSED\n"),
+		ui.NewButton("Resume", g.resume),
+		ui.NewButton("Main Menu", func() { g.state.ChangeState(StateMainMenu) }),
+	)
+	menu.OnBack = g.resume
+
+	g.openMenu(menu, constants.ScreenW/2-80, constants.ScreenH/2)
+}
+
+func (g *Game) resume() {
+	g.state.ChangeState(StatePlaying)
+}
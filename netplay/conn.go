@@ -0,0 +1,97 @@
+package netplay
+
+import (
+	"math/rand/v2"
+	"net"
+	"time"
+)
+
+const maxPacketSize = 512
+
+// Conn is a UDP socket talking to a single peer. It can also pretend to be a
+// bad network: outgoing packets are held back by Latency and dropped with the
+// probability Loss, which is how matches get tested over loopback.
+type Conn struct {
+	conn     *net.UDPConn
+	remote   *net.UDPAddr
+	latency  time.Duration
+	loss     float64
+	rng      *rand.Rand
+	incoming chan []byte
+}
+
+func Listen(localAddr, remoteAddr string, latency time.Duration, loss float64) (*Conn, error) {
+	local, err := net.ResolveUDPAddr("udp", localAddr)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := net.ResolveUDPAddr("udp", remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", local)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Conn{
+		conn:     conn,
+		remote:   remote,
+		latency:  latency,
+		loss:     loss,
+		rng:      rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0)),
+		incoming: make(chan []byte, 256),
+	}
+	go c.readLoop()
+
+	return c, nil
+}
+
+func (c *Conn) readLoop() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, _, err := c.conn.ReadFromUDP(buf)
+		if err != nil {
+			close(c.incoming)
+			return
+		}
+		packet := make([]byte, n)
+		copy(packet, buf[:n])
+
+		select {
+		case c.incoming <- packet:
+		default:
+		}
+	}
+}
+
+func (c *Conn) Send(packet []byte) {
+	if c.loss > 0 && c.rng.Float64() < c.loss {
+		return
+	}
+
+	if c.latency <= 0 {
+		c.conn.WriteToUDP(packet, c.remote)
+		return
+	}
+
+	delayed := make([]byte, len(packet))
+	copy(delayed, packet)
+	time.AfterFunc(c.latency, func() {
+		c.conn.WriteToUDP(delayed, c.remote)
+	})
+}
+
+// Receive returns the next packet that has arrived without waiting for one.
+func (c *Conn) Receive() ([]byte, bool) {
+	select {
+	case packet, ok := <-c.incoming:
+		return packet, ok
+	default:
+		return nil, false
+	}
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,61 @@
+package netplay
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	packetHello byte = iota + 1
+	packetInput
+)
+
+const inputHeaderSize = 1 + 4 + 4 + 8 + 4 + 1
+
+var errShortPacket = errors.New("netplay: short packet")
+
+// inputPacket carries the sender's inputs starting at StartFrame, and the
+// last frame of the receiver's inputs it already has so they aren't resent.
+// ConfirmedHash is the sender's simulation.Hash after ConfirmedFrame, a frame
+// no rollback can change anymore, so both sides can check they agree.
+type inputPacket struct {
+	Ack            int32
+	ConfirmedFrame int32
+	ConfirmedHash  uint64
+	StartFrame     int32
+	Inputs         []byte
+}
+
+func encodeHello() []byte {
+	return []byte{packetHello}
+}
+
+func encodeInput(p *inputPacket) []byte {
+	buf := make([]byte, inputHeaderSize+len(p.Inputs))
+	buf[0] = packetInput
+	binary.LittleEndian.PutUint32(buf[1:], uint32(p.Ack))
+	binary.LittleEndian.PutUint32(buf[5:], uint32(p.ConfirmedFrame))
+	binary.LittleEndian.PutUint64(buf[9:], p.ConfirmedHash)
+	binary.LittleEndian.PutUint32(buf[17:], uint32(p.StartFrame))
+	buf[21] = byte(len(p.Inputs))
+	copy(buf[inputHeaderSize:], p.Inputs)
+	return buf
+}
+
+func decodeInput(buf []byte) (*inputPacket, error) {
+	if len(buf) < inputHeaderSize {
+		return nil, errShortPacket
+	}
+	count := int(buf[21])
+	if len(buf) < inputHeaderSize+count {
+		return nil, errShortPacket
+	}
+
+	return &inputPacket{
+		Ack:            int32(binary.LittleEndian.Uint32(buf[1:])),
+		ConfirmedFrame: int32(binary.LittleEndian.Uint32(buf[5:])),
+		ConfirmedHash:  binary.LittleEndian.Uint64(buf[9:]),
+		StartFrame:     int32(binary.LittleEndian.Uint32(buf[17:])),
+		Inputs:         buf[inputHeaderSize : inputHeaderSize+count],
+	}, nil
+}
@@ -0,0 +1,300 @@
+// Package netplay plays two player matches over UDP with rollback. Two copies
+// of the game on one machine can play each other over loopback:
+//
+//	go run . -net-local :7000 -net-remote 127.0.0.1:7001 -net-player 0 -net-latency 50ms -net-loss 0.05
+//	go run . -net-local :7001 -net-remote 127.0.0.1:7000 -net-player 1 -net-latency 50ms -net-loss 0.05
+package netplay
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/replay"
+	"github.com/gassyrdaulet/go-fighting-game/simulation"
+)
+
+// Players is how many players an online match has: one on each machine.
+const Players = 2
+
+const (
+	maxPrediction      = 8
+	historyFrames      = 64
+	maxInputsPerPacket = 32
+	disconnectTimeout  = 5 * time.Second
+)
+
+type Config struct {
+	LocalAddr   string
+	RemoteAddr  string
+	LocalPlayer int
+	InputDelay  int
+	Latency     time.Duration
+	Loss        float64
+}
+
+// Session runs a two player match with rollback. The remote player's input
+// for a frame is guessed when it hasn't arrived yet; once it does and the
+// guess was wrong, the simulation is restored to that frame and stepped
+// forward again with the real inputs.
+type Session struct {
+	sim    *simulation.Simulation
+	conn   *Conn
+	local  int
+	remote int
+	delay  int
+
+	frame        int
+	localInputs  map[int]byte
+	remoteInputs map[int]byte
+	usedRemote   map[int]byte
+	states       map[int]*simulation.State
+	hashes       map[int]uint64
+
+	remoteConfirmed int
+	checkedFrame    int
+	peerAck         int
+	peerHashFrame   int
+	peerHash        uint64
+
+	connected bool
+	lastHeard time.Time
+
+	DesyncFrame int
+	Rollbacks   int
+}
+
+func NewSession(cfg Config, sim *simulation.Simulation) (*Session, error) {
+	if cfg.LocalPlayer < 0 || cfg.LocalPlayer >= Players {
+		return nil, fmt.Errorf("netplay: local player %d out of range", cfg.LocalPlayer)
+	}
+	if len(sim.Players) != Players {
+		return nil, fmt.Errorf("netplay: match has %d players, expected %d", len(sim.Players), Players)
+	}
+
+	conn, err := Listen(cfg.LocalAddr, cfg.RemoteAddr, cfg.Latency, cfg.Loss)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{
+		sim:             sim,
+		conn:            conn,
+		local:           cfg.LocalPlayer,
+		remote:          1 - cfg.LocalPlayer,
+		delay:           cfg.InputDelay,
+		localInputs:     make(map[int]byte),
+		remoteInputs:    make(map[int]byte),
+		usedRemote:      make(map[int]byte),
+		states:          make(map[int]*simulation.State),
+		hashes:          make(map[int]uint64),
+		remoteConfirmed: -1,
+		checkedFrame:    -1,
+		peerAck:         -1,
+		peerHashFrame:   -1,
+		DesyncFrame:     -1,
+	}
+
+	for f := 0; f < s.delay; f++ {
+		s.localInputs[f] = 0
+	}
+
+	return s, nil
+}
+
+func (s *Session) Close() error {
+	return s.conn.Close()
+}
+
+func (s *Session) Connected() bool {
+	return s.connected
+}
+
+func (s *Session) Disconnected() bool {
+	return s.connected && time.Since(s.lastHeard) > disconnectTimeout
+}
+
+func (s *Session) Frame() int {
+	return s.frame
+}
+
+// Poll keeps the connection alive without advancing the match, for when the
+// game is waiting for the peer or paused.
+func (s *Session) Poll() {
+	s.receive()
+	s.send()
+}
+
+// AdvanceFrame simulates the next frame with the local player's input. It
+// returns false and leaves the match where it is when the peer has fallen too
+// far behind to keep guessing its inputs.
+func (s *Session) AdvanceFrame(input base.Input) bool {
+	s.receive()
+	s.rollback()
+	s.checkDesync()
+
+	if s.frame-s.remoteConfirmed > maxPrediction {
+		s.send()
+		return false
+	}
+
+	s.localInputs[s.frame+s.delay] = replay.EncodeInput(input)
+	s.simulate(s.frame)
+	s.send()
+	s.prune()
+
+	return true
+}
+
+func (s *Session) simulate(frame int) {
+	s.states[frame] = s.sim.Save()
+
+	remote, ok := s.remoteInputs[frame]
+	if !ok {
+		remote = s.remoteInputs[s.remoteConfirmed]
+	}
+	s.usedRemote[frame] = remote
+
+	inputs := make([]base.Input, Players)
+	inputs[s.local] = replay.DecodeInput(s.localInputs[frame])
+	inputs[s.remote] = replay.DecodeInput(remote)
+
+	s.sim.Step(inputs)
+	s.hashes[frame] = s.sim.Hash()
+	s.frame = frame + 1
+}
+
+// rollback finds the first frame simulated with a wrong guess and replays
+// everything from there with the inputs that have arrived since.
+func (s *Session) rollback() {
+	last := min(s.remoteConfirmed, s.frame-1)
+
+	first := -1
+	for f := s.checkedFrame + 1; f <= last; f++ {
+		if s.usedRemote[f] != s.remoteInputs[f] {
+			first = f
+			break
+		}
+	}
+	if last > s.checkedFrame {
+		s.checkedFrame = last
+	}
+	if first < 0 {
+		return
+	}
+
+	state, ok := s.states[first]
+	if !ok {
+		return
+	}
+
+	target := s.frame
+	s.sim.Restore(state)
+	for f := first; f < target; f++ {
+		s.simulate(f)
+	}
+	s.Rollbacks++
+}
+
+// Settled reports whether every frame simulated so far ran on the peer's
+// real inputs, so nothing seen in the simulation can be rolled back anymore.
+func (s *Session) Settled() bool {
+	return s.confirmedFrame() >= s.frame-1
+}
+
+func (s *Session) confirmedFrame() int {
+	return min(s.remoteConfirmed, s.frame-1)
+}
+
+func (s *Session) checkDesync() {
+	if s.DesyncFrame >= 0 || s.peerHashFrame < 0 || s.peerHashFrame > s.confirmedFrame() {
+		return
+	}
+	if hash, ok := s.hashes[s.peerHashFrame]; ok && hash != s.peerHash {
+		s.DesyncFrame = s.peerHashFrame
+	}
+}
+
+func (s *Session) receive() {
+	for {
+		data, ok := s.conn.Receive()
+		if !ok || len(data) == 0 {
+			return
+		}
+		s.lastHeard = time.Now()
+		s.connected = true
+
+		if data[0] != packetInput {
+			continue
+		}
+		p, err := decodeInput(data)
+		if err != nil {
+			continue
+		}
+		s.handleInput(p)
+	}
+}
+
+func (s *Session) handleInput(p *inputPacket) {
+	for i, in := range p.Inputs {
+		f := int(p.StartFrame) + i
+		if f > s.remoteConfirmed {
+			s.remoteInputs[f] = in
+		}
+	}
+	for {
+		if _, ok := s.remoteInputs[s.remoteConfirmed+1]; !ok {
+			break
+		}
+		s.remoteConfirmed++
+	}
+
+	if int(p.Ack) > s.peerAck {
+		s.peerAck = int(p.Ack)
+	}
+	if int(p.ConfirmedFrame) > s.peerHashFrame {
+		s.peerHashFrame = int(p.ConfirmedFrame)
+		s.peerHash = p.ConfirmedHash
+	}
+}
+
+func (s *Session) send() {
+	if !s.connected {
+		s.conn.Send(encodeHello())
+		return
+	}
+
+	start := s.peerAck + 1
+	end := s.frame + s.delay
+	if end-start > maxInputsPerPacket {
+		end = start + maxInputsPerPacket
+	}
+
+	inputs := make([]byte, 0, max(end-start, 0))
+	for f := start; f < end; f++ {
+		inputs = append(inputs, s.localInputs[f])
+	}
+
+	confirmed := s.confirmedFrame()
+	s.conn.Send(encodeInput(&inputPacket{
+		Ack:            int32(s.remoteConfirmed),
+		ConfirmedFrame: int32(confirmed),
+		ConfirmedHash:  s.hashes[confirmed],
+		StartFrame:     int32(start),
+		Inputs:         inputs,
+	}))
+}
+
+func (s *Session) prune() {
+	old := s.frame - historyFrames
+	if old < 0 {
+		return
+	}
+	delete(s.localInputs, old)
+	delete(s.usedRemote, old)
+	delete(s.states, old)
+	delete(s.hashes, old)
+	if old < s.remoteConfirmed {
+		delete(s.remoteInputs, old)
+	}
+}
@@ -0,0 +1,88 @@
+package netplay
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gassyrdaulet/go-fighting-game/simulation/simtest"
+)
+
+// freeAddr returns a loopback address no one is listening on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.LocalAddr().String()
+}
+
+func TestSessionsAgreeOverBadNetwork(t *testing.T) {
+	const frames = 600
+	addrs := []string{freeAddr(t), freeAddr(t)}
+
+	sessions := make([]*Session, Players)
+	for i := range sessions {
+		s, err := NewSession(Config{
+			LocalAddr:   addrs[i],
+			RemoteAddr:  addrs[1-i],
+			LocalPlayer: i,
+			InputDelay:  2,
+			Latency:     10 * time.Millisecond,
+			Loss:        0.1,
+		}, simtest.New(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		sessions[i] = s
+	}
+
+	// confirmed[i] holds the hash of every frame session i has confirmed.
+	// Right after AdvanceFrame every confirmed frame has been rolled back
+	// and replayed with the real inputs, so the hash can't change anymore.
+	confirmed := []map[int]uint64{{}, {}}
+	deadline := time.Now().Add(30 * time.Second)
+
+	for sessions[0].Frame() < frames+maxPrediction || sessions[1].Frame() < frames+maxPrediction {
+		if time.Now().After(deadline) {
+			t.Fatalf("sessions stuck at frames %d and %d", sessions[0].Frame(), sessions[1].Frame())
+		}
+		for i, s := range sessions {
+			if !s.Connected() || s.Frame() >= frames+maxPrediction {
+				s.Poll()
+				continue
+			}
+			s.AdvanceFrame(simtest.Inputs(s.Frame())[i])
+			for f := s.confirmedFrame(); f >= 0; f-- {
+				if _, ok := confirmed[i][f]; ok {
+					break
+				}
+				confirmed[i][f] = s.hashes[f]
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	for i, s := range sessions {
+		if s.DesyncFrame >= 0 {
+			t.Errorf("player %d saw a desync at frame %d", i+1, s.DesyncFrame)
+		}
+	}
+	for f := range frames {
+		a, okA := confirmed[0][f]
+		b, okB := confirmed[1][f]
+		if !okA || !okB {
+			t.Fatalf("frame %d was never confirmed on both sides", f)
+		}
+		if a != b {
+			t.Fatalf("frame %d: hashes differ", f)
+		}
+	}
+	if sessions[0].Rollbacks+sessions[1].Rollbacks == 0 {
+		t.Error("no rollbacks; the inputs never went mispredicted")
+	}
+}
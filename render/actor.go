@@ -0,0 +1,115 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/entities/actor"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const debug = true
+
+func DrawActor(screen *ebiten.Image, a *actor.Actor, sprites SpriteSet, sx, sy float64) {
+	if debug {
+		DrawActorDebug(screen, a, sx, sy)
+	}
+	DrawHPBar(screen, a, sx, sy)
+	DrawFrame(screen, a.Animator, sprites, sx, sy, a.Direction == -1)
+}
+
+func DrawFrame(screen *ebiten.Image, a *base.Animator, sprites SpriteSet, x, y float64, flip bool) {
+	anim, ok := a.Animations[a.CurrentAnimation]
+	if !ok || anim == nil {
+		return
+	}
+	frames := sprites[a.CurrentAnimation]
+	if anim.FrameIndex >= len(frames) {
+		return
+	}
+	currentFrame := frames[anim.FrameIndex]
+	frameWidth := float64(currentFrame.Bounds().Dx())
+
+	op := &ebiten.DrawImageOptions{}
+	if flip {
+		op.GeoM.Scale(-a.SpriteScaleX, a.SpriteScaleY)
+		op.GeoM.Translate(x+(frameWidth/2)*a.SpriteScaleX-anim.XO, y-anim.YO)
+	} else {
+		op.GeoM.Scale(a.SpriteScaleX, a.SpriteScaleY)
+		op.GeoM.Translate(x-(frameWidth/2)*a.SpriteScaleX+anim.XO, y-anim.YO)
+	}
+	screen.DrawImage(currentFrame, op)
+}
+
+// teamColors are the HP bar colors of teams 1, 2 and so on.
+var teamColors = []color.RGBA{
+	{200, 45, 45, 255},
+	{45, 95, 205, 255},
+	{50, 170, 70, 255},
+	{215, 180, 40, 255},
+}
+
+// TeamColor is the color a team is shown in, repeating for teams past the
+// last color.
+func TeamColor(team int) color.RGBA {
+	if team <= 0 {
+		return teamColors[0]
+	}
+	return teamColors[(team-1)%len(teamColors)]
+}
+
+func DrawHPBar(screen *ebiten.Image, a *actor.Actor, sx, sy float64) {
+	w := 30
+	h := 4
+
+	ratio := float64(a.Hp) / float64(a.MaxHp)
+	newWidth := int(float64(w) * ratio)
+	if newWidth > 0 {
+		bar := ebiten.NewImage(newWidth, h)
+		bar.Fill(TeamColor(a.Team))
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(sx-float64(w/2), sy-10)
+
+		screen.DrawImage(bar, op)
+	}
+
+	if a.Character.Guard <= 0 || a.Guard >= a.Character.Guard {
+		return
+	}
+	guardWidth := int(float64(w) * a.Guard / a.Character.Guard)
+	if guardWidth > 0 {
+		bar := ebiten.NewImage(guardWidth, 2)
+		bar.Fill(color.RGBA{225, 225, 225, 255})
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(sx-float64(w/2), sy-10+float64(h)+1)
+
+		screen.DrawImage(bar, op)
+	}
+}
+
+func DrawActorDebug(screen *ebiten.Image, a *actor.Actor, sx, sy float64) {
+	ebitenutil.DebugPrintAt(
+		screen,
+		fmt.Sprintf("Animation & Attacking: (%s, %t)", a.CurrentAnimation, a.Attacking),
+		int(sx-30),
+		int(sy-30),
+	)
+
+	for _, r := range a.HurtBoxes() {
+		drawBox(screen, a, r, sx, sy, color.RGBA{60, 200, 60, 255})
+	}
+	for _, r := range a.HitBoxes() {
+		drawBox(screen, a, r, sx, sy, color.RGBA{220, 40, 40, 255})
+	}
+}
+
+// drawBox outlines a world space box of the actor drawn at sx, sy.
+func drawBox(screen *ebiten.Image, a *actor.Actor, r image.Rectangle, sx, sy float64, clr color.Color) {
+	x := sx + float64(r.Min.X) - a.X
+	y := sy + float64(r.Min.Y) - a.Y
+	vector.StrokeRect(screen, float32(x), float32(y), float32(r.Dx()), float32(r.Dy()), 1, clr, false)
+}
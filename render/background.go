@@ -1,9 +1,11 @@
-package base
+package render
 
 import (
 	"math"
 
+	"github.com/gassyrdaulet/go-fighting-game/base"
 	c "github.com/gassyrdaulet/go-fighting-game/constants"
+	"github.com/gassyrdaulet/go-fighting-game/levels"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -19,7 +21,7 @@ type Background struct {
 	BaseY    float64
 }
 
-func (bg *Background) Draw(screen *ebiten.Image, cam *Camera) {
+func (bg *Background) Draw(screen *ebiten.Image, cam *base.Camera) {
 	camX, camY := cam.TopLeft()
 
 	screenW := float64(c.ScreenW)
@@ -53,3 +55,18 @@ func (bg *Background) Draw(screen *ebiten.Image, cam *Camera) {
 		}
 	}
 }
+
+func BuildBackground(defs []levels.BackgroundDef, groundY float64) *Background {
+    layers := []*BackgroundLayer{}
+
+    for _, bg := range defs {
+        layers = append(layers, &BackgroundLayer{
+            Image:    MustLoad(bg.Image),
+            ScrollX:  bg.ScrollX,
+            ScrollY:  bg.ScrollY,
+            StretchY: bg.StretchY,
+        })
+    }
+
+    return &Background{Layers: layers, BaseY: groundY}
+}
@@ -0,0 +1,11 @@
+package render
+
+import (
+	"github.com/gassyrdaulet/go-fighting-game/entities/projectile"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// DrawProjectile draws a projectile with the sprites of whoever threw it.
+func DrawProjectile(screen *ebiten.Image, p *projectile.Projectile, sprites SpriteSet, sx, sy float64) {
+	DrawFrame(screen, p.Animator, sprites, sx, sy, p.Direction == -1)
+}
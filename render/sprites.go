@@ -0,0 +1,61 @@
+package render
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/characters"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// SpriteSet holds the frames of every animation of a single character, keyed
+// by animation name like base.Animator.Animations.
+type SpriteSet map[string][]*ebiten.Image
+
+func loadFrames(cfg *base.AnimationConfig) ([]*ebiten.Image, error) {
+	img, err := LoadImage(cfg.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load file: %s %s", cfg.Name, cfg.FilePath)
+	}
+
+	frames := make([]*ebiten.Image, cfg.Count)
+	for i := 0; i < cfg.Count; i++ {
+		sx0 := i*cfg.Width + cfg.StartX
+		sy0 := cfg.StartY
+		sx1 := sx0 + cfg.Width
+		sy1 := sy0 + cfg.Height
+
+		sub := img.SubImage(image.Rect(sx0, sy0, sx1, sy1)).(*ebiten.Image)
+		frames[i] = sub
+	}
+	return frames, nil
+}
+
+func LoadSpriteSet(cfgs []base.AnimationConfig) (SpriteSet, error) {
+	sprites := SpriteSet{}
+
+	for _, cfg := range cfgs {
+		frames, err := loadFrames(&cfg)
+		if err != nil {
+			return nil, err
+		}
+		sprites[cfg.Name] = frames
+	}
+
+	return sprites, nil
+}
+
+func LoadSprites(chars map[string]*characters.Character) (map[string]SpriteSet, error) {
+	result := make(map[string]SpriteSet, len(chars))
+
+	for id, char := range chars {
+		sprites, err := LoadSpriteSet(char.AnimationsConfigs)
+		if err != nil {
+			return nil, fmt.Errorf("character %s: %w", id, err)
+		}
+		result[id] = sprites
+	}
+
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package render
+
+import (
+	"image/color"
+
+	"github.com/gassyrdaulet/go-fighting-game/levels"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Thumbnail draws the whole tile map of a level with its own tiles, shrunk to
+// fit in maxW by maxH pixels.
+func Thumbnail(data levels.TileMapData, maxW, maxH int) (*ebiten.Image, error) {
+	tiles, err := NewTileMapRenderer(data.Tileset)
+	if err != nil {
+		return nil, err
+	}
+	m, err := levels.BuildTileMapFromLines(data)
+	if err != nil {
+		return nil, err
+	}
+
+	scale := min(
+		float64(maxW)/float64(m.Width*m.TileSize),
+		float64(maxH)/float64(m.Height*m.TileSize),
+	)
+	w := max(int(float64(m.Width*m.TileSize)*scale), 1)
+	h := max(int(float64(m.Height*m.TileSize)*scale), 1)
+
+	thumb := ebiten.NewImage(w, h)
+	thumb.Fill(color.RGBA{30, 30, 45, 255})
+
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			img := tiles.Textures[m.Tiles[y][x].ID]
+			if m.Tiles[y][x].ID == 0 || img == nil {
+				continue
+			}
+
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(x*m.TileSize), float64(y*m.TileSize))
+			op.GeoM.Scale(scale, scale)
+			op.Filter = ebiten.FilterLinear
+			thumb.DrawImage(img, op)
+		}
+	}
+
+	return thumb, nil
+}
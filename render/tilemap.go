@@ -0,0 +1,74 @@
+package render
+
+import (
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/base/physics"
+	"github.com/gassyrdaulet/go-fighting-game/levels"
+	"github.com/gassyrdaulet/go-fighting-game/levels/tileset"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type TileMapRenderer struct {
+	Textures map[int]*ebiten.Image
+}
+
+func NewTileMapRenderer(tilesetName string) (*TileMapRenderer, error) {
+	ts, err := tileset.LoadTileSet(tilesetName)
+	if err != nil {
+		return nil, err
+	}
+
+	textures := make(map[int]*ebiten.Image, len(ts.Tiles))
+	for _, tile := range ts.Tiles {
+		img, err := LoadImage(tile.Image)
+		if err != nil {
+			return nil, err
+		}
+		textures[tile.ID] = img
+	}
+
+	return &TileMapRenderer{Textures: textures}, nil
+}
+
+func (r *TileMapRenderer) Draw(screen *ebiten.Image, m *base.TileMap, cam *base.Camera) {
+	camX, camY := cam.TopLeft()
+
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			tile := m.Tiles[y][x]
+			img := r.Textures[tile.ID]
+			if tile.ID == 0 || img == nil {
+				continue
+			}
+
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(
+				float64(x*m.TileSize)-camX,
+				float64(y*m.TileSize)-camY,
+			)
+			screen.DrawImage(img, op)
+		}
+	}
+}
+
+// DrawPlatforms draws every moving platform with the tile its level gives it,
+// repeated over its whole size.
+func (r *TileMapRenderer) DrawPlatforms(screen *ebiten.Image, defs []levels.PlatformData, platforms []*physics.Platform, cam *base.Camera) {
+	camX, camY := cam.TopLeft()
+
+	for i, p := range platforms {
+		img := r.Textures[defs[i].Tile]
+		if img == nil {
+			continue
+		}
+		size := img.Bounds().Dx()
+		left := p.X - p.Width/2
+		for y := 0.0; y < p.Height; y += float64(size) {
+			for x := 0.0; x < p.Width; x += float64(size) {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(left+x-camX, p.Y+y-camY)
+				screen.DrawImage(img, op)
+			}
+		}
+	}
+}
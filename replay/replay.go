@@ -0,0 +1,215 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/simulation"
+)
+
+// Version is bumped whenever the file layout or the simulation changes in a
+// way that makes older replays play out differently.
+const Version = 22
+
+// Replay is everything needed to play a match again: how it was set up and
+// what every player pressed on every tick. Inputs[i] holds one byte per tick
+// for player i.
+//
+// Hashes holds simulation.Hash after every tick and Digests[i] the
+// simulation.FieldDigest of player i after every tick, laid out back to back,
+// so playback can tell where and how it went off the recorded match.
+type Replay struct {
+	Version    int              `json:"version"`
+	Level      string           `json:"level"`
+	Characters []string         `json:"characters"`
+	Teams      []int            `json:"teams"`
+	Rules      simulation.Rules `json:"rules"`
+	Inputs     [][]byte         `json:"inputs"`
+	Hashes     []uint64         `json:"hashes"`
+	Digests    [][]byte         `json:"digests"`
+}
+
+func (r *Replay) Ticks() int {
+	if len(r.Inputs) == 0 {
+		return 0
+	}
+	return len(r.Inputs[0])
+}
+
+func (r *Replay) Input(player, tick int) base.Input {
+	if player >= len(r.Inputs) || tick >= len(r.Inputs[player]) {
+		return base.Input{}
+	}
+	return DecodeInput(r.Inputs[player][tick])
+}
+
+const (
+	bitLeft byte = 1 << iota
+	bitRight
+	bitUp
+	bitDown
+	bitAttack
+	bitBlock
+)
+
+func EncodeInput(in base.Input) byte {
+	var b byte
+	if in.Left {
+		b |= bitLeft
+	}
+	if in.Right {
+		b |= bitRight
+	}
+	if in.Up {
+		b |= bitUp
+	}
+	if in.Down {
+		b |= bitDown
+	}
+	if in.Attack {
+		b |= bitAttack
+	}
+	if in.Block {
+		b |= bitBlock
+	}
+	return b
+}
+
+func DecodeInput(b byte) base.Input {
+	return base.Input{
+		Left:   b&bitLeft != 0,
+		Right:  b&bitRight != 0,
+		Up:     b&bitUp != 0,
+		Down:   b&bitDown != 0,
+		Attack: b&bitAttack != 0,
+		Block:  b&bitBlock != 0,
+	}
+}
+
+// Desync is the first difference between a replay and its playback. Player
+// is -1 when only the overall hash tells them apart.
+type Desync struct {
+	Tick   int
+	Player int
+	Field  string
+}
+
+func (d *Desync) Error() string {
+	if d.Player < 0 {
+		return fmt.Sprintf("desync at tick %d", d.Tick)
+	}
+	return fmt.Sprintf("desync at tick %d: player %d field %s", d.Tick, d.Player+1, d.Field)
+}
+
+// Verify compares the simulation right after a Step with what was recorded
+// for the same tick and returns nil when they match.
+func (r *Replay) Verify(sim *simulation.Simulation) *Desync {
+	tick := sim.Tick - 1
+	if tick < 0 || tick >= len(r.Hashes) || r.Hashes[tick] == sim.Hash() {
+		return nil
+	}
+
+	for i, a := range sim.Players {
+		if i >= len(r.Digests) {
+			break
+		}
+		fields := simulation.ActorState(a, sim.Players)
+		digest := simulation.FieldDigest(fields)
+		start := tick * len(digest)
+		if start+len(digest) > len(r.Digests[i]) {
+			break
+		}
+		recorded := r.Digests[i][start : start+len(digest)]
+		for f := range digest {
+			if digest[f] != recorded[f] {
+				return &Desync{Tick: tick, Player: i, Field: fields[f].Name}
+			}
+		}
+	}
+
+	return &Desync{Tick: tick, Player: -1}
+}
+
+type Recorder struct {
+	replay *Replay
+	sim    *simulation.Simulation
+}
+
+func NewRecorder(sim *simulation.Simulation) *Recorder {
+	chars := make([]string, len(sim.Players))
+	teams := make([]int, len(sim.Players))
+	for i, p := range sim.Players {
+		chars[i] = p.Character.ID
+		teams[i] = p.Team
+	}
+
+	return &Recorder{
+		replay: &Replay{
+			Version:    Version,
+			Level:      sim.LevelName,
+			Characters: chars,
+			Teams:      teams,
+			Rules:      sim.Rules,
+			Inputs:     make([][]byte, len(sim.Players)),
+			Digests:    make([][]byte, len(sim.Players)),
+		},
+		sim: sim,
+	}
+}
+
+// Record stores the inputs of one tick and the state they led to. It has to
+// be called right after Simulation.Step with exactly what was passed to it.
+func (r *Recorder) Record(inputs []base.Input) {
+	for i := range r.replay.Inputs {
+		var in base.Input
+		if i < len(inputs) {
+			in = inputs[i]
+		}
+		r.replay.Inputs[i] = append(r.replay.Inputs[i], EncodeInput(in))
+	}
+
+	r.replay.Hashes = append(r.replay.Hashes, r.sim.Hash())
+	for i, a := range r.sim.Players {
+		digest := simulation.FieldDigest(simulation.ActorState(a, r.sim.Players))
+		r.replay.Digests[i] = append(r.replay.Digests[i], digest...)
+	}
+}
+
+func (r *Recorder) Replay() *Replay {
+	return r.replay
+}
+
+func Save(path string, r *Replay) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func Load(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Replay
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+
+	if r.Version != Version {
+		return nil, fmt.Errorf("replay %s: version %d, expected %d", path, r.Version, Version)
+	}
+	if len(r.Inputs) != len(r.Characters) {
+		return nil, fmt.Errorf("replay %s: %d input streams for %d characters", path, len(r.Inputs), len(r.Characters))
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,64 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/simulation"
+	"github.com/gassyrdaulet/go-fighting-game/simulation/simtest"
+)
+
+// record plays a match and returns its replay.
+func record(t *testing.T, ticks int) *Replay {
+	sim := simtest.New(t)
+	rec := NewRecorder(sim)
+	for tick := range ticks {
+		inputs := simtest.Inputs(tick)
+		sim.Step(inputs)
+		rec.Record(inputs)
+	}
+	return rec.Replay()
+}
+
+// play steps a new simulation through rp, calling tamper before every step,
+// and returns the first desync Verify reports.
+func play(t *testing.T, rp *Replay, tamper func(sim *simulation.Simulation)) *Desync {
+	sim := simtest.New(t)
+	for tick := range rp.Ticks() {
+		tamper(sim)
+		inputs := make([]base.Input, len(sim.Players))
+		for i := range inputs {
+			inputs[i] = rp.Input(i, tick)
+		}
+		sim.Step(inputs)
+		if d := rp.Verify(sim); d != nil {
+			return d
+		}
+	}
+	return nil
+}
+
+func TestVerifyPlayback(t *testing.T) {
+	rp := record(t, 1500)
+
+	if d := play(t, rp, func(*simulation.Simulation) {}); d != nil {
+		t.Fatalf("playback of an untouched replay: %v", d)
+	}
+}
+
+func TestVerifyFindsChangedField(t *testing.T) {
+	rp := record(t, 1500)
+
+	const tick = 400
+	d := play(t, rp, func(sim *simulation.Simulation) {
+		if sim.Tick == tick {
+			sim.Players[1].Weight += 0.5
+		}
+	})
+	if d == nil {
+		t.Fatal("no desync after changing a field")
+	}
+	if d.Tick != tick || d.Player != 1 || d.Field != "Weight" {
+		t.Errorf("desync = %+v, want tick %d, player 1, field Weight", *d, tick)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/characters"
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+	"github.com/gassyrdaulet/go-fighting-game/render"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// maxTeams is how many teams players can pick from on the select screen.
+const maxTeams = 4
+
+// selectScreen is where every player picks a character before a match. Each
+// slot played by a person moves its own cursor with its own controller:
+// Left/Right over the characters, Up/Down over the teams, Attack to confirm
+// and Block to take it back. Block before confirming, or pause, goes back to
+// the main menu. Bot slots keep the character they are given.
+type selectScreen struct {
+	ids       []string
+	cursors   []int
+	teams     []int
+	ready     []bool
+	prev      []base.Input
+	animators []*base.Animator
+}
+
+func (g *Game) initSelect() {
+	if err := g.loadCharacters(); err != nil {
+		log.Println(err)
+		g.state.ChangeState(StateMainMenu)
+		return
+	}
+
+	ids := make([]string, 0, len(g.playersChars))
+	for id := range g.playersChars {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	n := g.slots.Len()
+	s := selectScreen{
+		ids:       ids,
+		cursors:   make([]int, n),
+		teams:     make([]int, n),
+		ready:     make([]bool, n),
+		prev:      make([]base.Input, n),
+		animators: make([]*base.Animator, n),
+	}
+	for slot := range n {
+		s.cursors[slot] = slot % len(ids)
+		s.teams[slot] = slot%maxTeams + 1
+		s.ready[slot] = g.isBot(slot)
+		// The attack that picked the level may still be held down; it
+		// shouldn't also ready the player.
+		s.prev[slot] = g.slots.Slot(slot).GetInput()
+		s.animators[slot] = idleAnimator(g.playersChars[ids[s.cursors[slot]]])
+	}
+	g.selection = s
+}
+
+// idleAnimator plays the idle animation of char on its own, without an actor,
+// at twice the size it has in a match.
+func idleAnimator(char *characters.Character) *base.Animator {
+	animations := map[string]*base.Animation{}
+	if idle, ok := char.Animations["idle"]; ok {
+		copied := *idle
+		animations["idle"] = &copied
+	}
+	animator := base.NewAnimator(animations)
+	animator.SpriteScaleX, animator.SpriteScaleY = 2, 2
+	return animator
+}
+
+func (g *Game) updateSelect() {
+	s := &g.selection
+
+	if g.nav.Pause {
+		g.state.ChangeState(StateMainMenu)
+		return
+	}
+
+	for slot := range s.cursors {
+		if g.isBot(slot) {
+			continue
+		}
+
+		in := g.slots.Slot(slot).GetInput()
+		prev := s.prev[slot]
+		s.prev[slot] = in
+
+		if in.Block && !prev.Block {
+			if !s.ready[slot] {
+				g.state.ChangeState(StateMainMenu)
+				return
+			}
+			s.ready[slot] = false
+			continue
+		}
+		if s.ready[slot] {
+			continue
+		}
+
+		cursor := s.cursors[slot]
+		if in.Left && !prev.Left {
+			cursor = (cursor + len(s.ids) - 1) % len(s.ids)
+		}
+		if in.Right && !prev.Right {
+			cursor = (cursor + 1) % len(s.ids)
+		}
+		if cursor != s.cursors[slot] {
+			s.cursors[slot] = cursor
+			s.animators[slot] = idleAnimator(g.playersChars[s.ids[cursor]])
+		}
+
+		if in.Up && !prev.Up {
+			s.teams[slot] = s.teams[slot]%maxTeams + 1
+		}
+		if in.Down && !prev.Down {
+			s.teams[slot] = (s.teams[slot]+maxTeams-2)%maxTeams + 1
+		}
+
+		if in.Attack && !prev.Attack {
+			s.ready[slot] = true
+		}
+	}
+
+	for _, animator := range s.animators {
+		animator.UpdateFrame("idle")
+	}
+
+	for _, ready := range s.ready {
+		if !ready {
+			return
+		}
+	}
+
+	g.charIDs = make([]string, len(s.cursors))
+	for slot, cursor := range s.cursors {
+		g.charIDs[slot] = s.ids[cursor]
+	}
+	g.teams = append([]int(nil), s.teams...)
+	g.state.ChangeState(StatePlaying)
+}
+
+func (g *Game) drawSelect(screen *ebiten.Image) {
+	s := &g.selection
+
+	ebitenutil.DebugPrintAt(screen, "CHARACTER SELECT", constants.ScreenW/2-48, 10)
+
+	width := constants.ScreenW / len(s.cursors)
+	for slot, cursor := range s.cursors {
+		char := g.playersChars[s.ids[cursor]]
+		x := slot * width
+
+		status := ""
+		if g.isBot(slot) {
+			status = "CPU"
+		} else if s.ready[slot] {
+			status = "READY"
+		}
+
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("P%d  < %s >  %s", slot+1, char.Name, status), x+20, 40)
+		render.DrawFrame(screen, s.animators[slot], g.sprites[char.ID], float64(x+width/2), 90, false)
+		ebitenutil.DebugPrintAt(
+			screen,
+			fmt.Sprintf(
+				"Speed  %.1f\nJump   %.1f\nDamage %d\nHP     %d\nTeam   %d",
+				char.Speed,
+				-char.JumpForce,
+				char.Combo[0].Damage,
+				char.MaxHP,
+				s.teams[slot],
+			),
+			x+20,
+			180,
+		)
+	}
+
+	ebitenutil.DebugPrintAt(
+		screen,
+		"[Left/Right] Character  [Up/Down] Team\n[Attack] Ready  [Block] Change/Back  [Esc/Start] Back",
+		20,
+		constants.ScreenH-50,
+	)
+}
@@ -0,0 +1,185 @@
+package simulation
+
+import (
+	"hash/fnv"
+	"math"
+	"slices"
+
+	"github.com/gassyrdaulet/go-fighting-game/base/physics"
+	"github.com/gassyrdaulet/go-fighting-game/entities/actor"
+	"github.com/gassyrdaulet/go-fighting-game/entities/projectile"
+)
+
+// StateField is one named piece of an actor's state flattened to its bits, so
+// two simulations can be compared field by field.
+type StateField struct {
+	Name string
+	Bits uint64
+}
+
+// ActorState flattens a, one of players. Who last hit it is hashed as their
+// index in players, or -1 when no one has.
+func ActorState(a *actor.Actor, players []*actor.Actor) []StateField {
+	lastHitBy := -1
+	if a.LastHitBy != nil {
+		lastHitBy = slices.Index(players, a.LastHitBy)
+	}
+
+	var shot string
+	if a.Shot != nil {
+		shot = a.Shot.Name
+	}
+
+	fields := []StateField{
+		{"X", math.Float64bits(a.X)},
+		{"Y", math.Float64bits(a.Y)},
+		{"VX", math.Float64bits(a.VX)},
+		{"VY", math.Float64bits(a.VY)},
+		{"Width", math.Float64bits(a.Width)},
+		{"Height", math.Float64bits(a.Height)},
+		{"OnGround", boolBits(a.OnGround)},
+		{"Weight", math.Float64bits(a.Weight)},
+		{"Hp", uint64(a.Hp)},
+		{"Dead", boolBits(a.Dead)},
+		{"Direction", uint64(a.Direction)},
+		{"JumpForceCurrent", math.Float64bits(a.JumpForceCurrent)},
+		{"ChargingJump", boolBits(a.ChargingJump)},
+		{"ChargingJumpTicks", uint64(a.ChargingJumpTicks)},
+		{"Attacking", boolBits(a.Attacking)},
+		{"AttackTicks", uint64(a.AttackTicks)},
+		{"AttackCooldownTicks", uint64(a.AttackCooldownTicks)},
+		{"Fired", boolBits(a.Fired)},
+		{"Shot", stringBits(shot)},
+		{"Dying", boolBits(a.Dying)},
+		{"DyingTicks", uint64(a.DyingTicks)},
+		{"Hurting", boolBits(a.Hurting)},
+		{"HurtingTicks", uint64(a.HurtingTicks)},
+		{"Crouching", boolBits(a.Crouching)},
+		{"ComboIndex", uint64(a.ComboIndex)},
+		{"ComboQueued", boolBits(a.ComboQueued)},
+		{"HitMask", a.HitMask},
+		{"Blocking", boolBits(a.Blocking)},
+		{"BlockTicks", uint64(a.BlockTicks)},
+		{"Guard", math.Float64bits(a.Guard)},
+		{"ParryTicks", uint64(a.ParryTicks)},
+		{"Stunned", boolBits(a.Stunned)},
+		{"StunTicks", uint64(a.StunTicks)},
+		{"Team", uint64(a.Team)},
+		{"Stocks", uint64(a.Stocks)},
+		{"LastHitBy", uint64(lastHitBy)},
+		{"DamageDealt", uint64(a.DamageDealt)},
+		{"KOs", uint64(a.KOs)},
+		{"Falls", uint64(a.Falls)},
+		{"RoundsWon", uint64(a.RoundsWon)},
+		{"CurrentAnimation", stringBits(a.CurrentAnimation)},
+	}
+
+	var frameIndex, frameTick int
+	if anim, ok := a.Animations[a.CurrentAnimation]; ok {
+		frameIndex, frameTick = anim.FrameIndex, anim.FrameTick
+	}
+	fields = append(fields,
+		StateField{"FrameIndex", uint64(frameIndex)},
+		StateField{"FrameTick", uint64(frameTick)},
+	)
+
+	return fields
+}
+
+func ProjectileState(p *projectile.Projectile) []StateField {
+	fields := []StateField{
+		{"Name", stringBits(p.Def.Name)},
+		{"Owner", uint64(p.Owner)},
+		{"Team", uint64(p.Team)},
+		{"X", math.Float64bits(p.X)},
+		{"Y", math.Float64bits(p.Y)},
+		{"VX", math.Float64bits(p.VX)},
+		{"VY", math.Float64bits(p.VY)},
+		{"Direction", uint64(p.Direction)},
+		{"Ticks", uint64(p.Ticks)},
+	}
+
+	var frameIndex, frameTick int
+	if anim, ok := p.Animations[p.CurrentAnimation]; ok {
+		frameIndex, frameTick = anim.FrameIndex, anim.FrameTick
+	}
+	return append(fields,
+		StateField{"FrameIndex", uint64(frameIndex)},
+		StateField{"FrameTick", uint64(frameTick)},
+	)
+}
+
+func PlatformState(p *physics.Platform) []StateField {
+	return []StateField{
+		{"X", math.Float64bits(p.X)},
+		{"Y", math.Float64bits(p.Y)},
+		{"DX", math.Float64bits(p.DX)},
+		{"DY", math.Float64bits(p.DY)},
+		{"Target", uint64(p.Target)},
+		{"Reverse", boolBits(p.Reverse)},
+		{"WaitTicks", uint64(p.WaitTicks)},
+	}
+}
+
+// Hash is a checksum of the whole simulation state after the last Step.
+func (s *Simulation) Hash() uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+
+	write := func(v uint64) {
+		for i := range buf {
+			buf[i] = byte(v >> (8 * i))
+		}
+		h.Write(buf[:])
+	}
+
+	write(uint64(s.Tick))
+	write(uint64(s.Round))
+	write(uint64(s.Countdown))
+	write(uint64(s.Clock))
+	write(boolBits(s.Over))
+	write(uint64(s.Winner))
+	write(boolBits(s.SuddenDeath))
+	for _, a := range s.Players {
+		for _, f := range ActorState(a, s.Players) {
+			write(f.Bits)
+		}
+	}
+	write(uint64(len(s.Projectiles)))
+	for _, p := range s.Projectiles {
+		for _, f := range ProjectileState(p) {
+			write(f.Bits)
+		}
+	}
+	for _, p := range s.World.Platforms {
+		for _, f := range PlatformState(p) {
+			write(f.Bits)
+		}
+	}
+
+	return h.Sum64()
+}
+
+// FieldDigest squeezes every field into a single byte. It is too weak to
+// detect a desync on its own, but once Hash says the states differ it points
+// at the field that changed.
+func FieldDigest(fields []StateField) []byte {
+	digest := make([]byte, len(fields))
+	for i, f := range fields {
+		digest[i] = byte(f.Bits ^ f.Bits>>8 ^ f.Bits>>16 ^ f.Bits>>24 ^ f.Bits>>32 ^ f.Bits>>40 ^ f.Bits>>48 ^ f.Bits>>56)
+	}
+	return digest
+}
+
+func boolBits(v bool) uint64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func stringBits(v string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(v))
+	return h.Sum64()
+}
@@ -0,0 +1,71 @@
+package simulation_test
+
+import (
+	"testing"
+
+	"github.com/gassyrdaulet/go-fighting-game/simulation"
+	"github.com/gassyrdaulet/go-fighting-game/simulation/simtest"
+)
+
+func TestHashSameInputs(t *testing.T) {
+	a := simtest.New(t)
+	b := simtest.New(t)
+
+	for tick := range 2000 {
+		a.Step(simtest.Inputs(tick))
+		b.Step(simtest.Inputs(tick))
+		if a.Hash() != b.Hash() {
+			t.Fatalf("tick %d: hashes differ for the same inputs", tick)
+		}
+	}
+}
+
+func TestFieldDigestNamesTheChangedField(t *testing.T) {
+	sim := simtest.New(t)
+	for tick := range 300 {
+		sim.Step(simtest.Inputs(tick))
+	}
+
+	hash := sim.Hash()
+	digest := simulation.FieldDigest(simulation.ActorState(sim.Players[1], sim.Players))
+
+	sim.Players[1].Hp--
+	if sim.Hash() == hash {
+		t.Error("Hash didn't change with a player's hp")
+	}
+	changed := simulation.FieldDigest(simulation.ActorState(sim.Players[1], sim.Players))
+	for i, f := range simulation.ActorState(sim.Players[1], sim.Players) {
+		if (digest[i] != changed[i]) != (f.Name == "Hp") {
+			t.Errorf("FieldDigest of %s changed = %v", f.Name, digest[i] != changed[i])
+		}
+	}
+}
+
+func TestHashSameInputsWithBodyCollision(t *testing.T) {
+	rules := simtest.Rules
+	rules.BodyCollision = true
+	a := simtest.NewWithRules(t, rules)
+	b := simtest.NewWithRules(t, rules)
+
+	for tick := range 2000 {
+		a.Step(simtest.Inputs(tick))
+		b.Step(simtest.Inputs(tick))
+		if a.Hash() != b.Hash() {
+			t.Fatalf("tick %d: hashes differ for the same inputs", tick)
+		}
+	}
+}
+
+func TestHashSeesWhoLastHit(t *testing.T) {
+	sim := simtest.New(t)
+	hashes := map[uint64]bool{sim.Hash(): true}
+
+	for i, by := range sim.Players {
+		sim.Players[0].LastHitBy = by
+		if h := sim.Hash(); hashes[h] {
+			t.Errorf("Hash didn't change with player 1 last hit by player %d", i+1)
+		} else {
+			hashes[h] = true
+		}
+	}
+}
@@ -0,0 +1,5 @@
+package simulation
+
+// End ends the current round with leaders ahead, as running out of stocks or
+// time would.
+func (s *Simulation) End(leaders []int) { s.end(leaders) }
@@ -0,0 +1,219 @@
+package simulation
+
+import (
+	"slices"
+
+	"github.com/gassyrdaulet/go-fighting-game/constants"
+	"github.com/gassyrdaulet/go-fighting-game/levels"
+)
+
+// Rules are how a match is played. Every player starts with Stocks lives and
+// respawns at its spawn point until they run out; the last team with stocks
+// left wins. TimeLimit, in ticks, ends the match early in favor of the team
+// with the most stocks left, or in sudden death between the teams tied for it
+// when SuddenDeath is on. A zero TimeLimit never runs out.
+//
+// With Rounds above one the match is played in rounds, each starting over
+// with everyone back at the spawn points, until a team has won Rounds of
+// them. Drawn rounds count towards the MaxRounds a match lasts at most; the
+// team with the most rounds won by then takes the match, which is drawn when
+// several tie.
+//
+// With BodyCollision the players push each other aside instead of walking
+// through each other, and can land and stomp on each other's heads.
+type Rules struct {
+	Stocks        int  `json:"stocks"`
+	Rounds        int  `json:"rounds"`
+	TimeLimit     int  `json:"timeLimit"`
+	SuddenDeath   bool `json:"suddenDeath"`
+	FriendlyFire  bool `json:"friendlyFire"`
+	BodyCollision bool `json:"bodyCollision"`
+}
+
+// suddenDeathHp is what the players left in sudden death are down to, so the
+// next hit decides the match.
+const suddenDeathHp = 1
+
+// roundCountdown is how long players wait at their spawn points before a
+// round starts.
+const roundCountdown = 3 * constants.TicksPerSecond
+
+// judge counts the falls and KOs of everyone who died this tick, respawns the
+// players with stocks left and ends the match once it is decided.
+func (s *Simulation) judge() {
+	for i, a := range s.Players {
+		if !a.Dead || a.Stocks == 0 {
+			continue
+		}
+		a.Falls++
+		if a.LastHitBy != nil && a.LastHitBy != a {
+			a.LastHitBy.KOs++
+		}
+		a.Stocks--
+		if a.Stocks > 0 {
+			spawn := levels.Spawn(s.Level.Spawns, i)
+			a.Respawn(spawn.X, spawn.Y)
+		}
+	}
+
+	if s.Over {
+		return
+	}
+
+	teams := s.teamStocks()
+	if len(teams) < 2 {
+		// A match with a single team has no one to win against.
+		return
+	}
+	standing := 0
+	for _, stocks := range teams {
+		if stocks > 0 {
+			standing++
+		}
+	}
+	if standing <= 1 {
+		s.end(s.leaders(teams))
+		return
+	}
+
+	if s.Rules.TimeLimit > 0 && s.Clock >= s.Rules.TimeLimit && !s.SuddenDeath {
+		leaders := s.leaders(teams)
+		if len(leaders) == 1 || !s.Rules.SuddenDeath {
+			s.end(leaders)
+			return
+		}
+		s.startSuddenDeath(leaders)
+	}
+}
+
+// teamStocks adds up the stocks left of every team in the match.
+func (s *Simulation) teamStocks() map[int]int {
+	teams := make(map[int]int)
+	for _, a := range s.Players {
+		teams[a.Team] += a.Stocks
+	}
+	return teams
+}
+
+// leaders are the teams with the most stocks left, in team order.
+func (s *Simulation) leaders(teams map[int]int) []int {
+	best := 0
+	for _, stocks := range teams {
+		best = max(best, stocks)
+	}
+	var leaders []int
+	for _, a := range s.Players {
+		if teams[a.Team] == best && best > 0 && !slices.Contains(leaders, a.Team) {
+			leaders = append(leaders, a.Team)
+		}
+	}
+	return leaders
+}
+
+// end finishes the round, won by the only leader or drawn between several.
+// The match is over with it unless it is played in rounds, no team has won
+// enough of them yet and there are rounds left to play.
+func (s *Simulation) end(leaders []int) {
+	winner := 0
+	if len(leaders) == 1 {
+		winner = leaders[0]
+	}
+
+	if s.Rules.Rounds > 1 {
+		if winner != 0 {
+			for _, a := range s.Players {
+				if a.Team == winner {
+					a.RoundsWon++
+				}
+			}
+		}
+		if winner == 0 || s.RoundsWon(winner) < s.Rules.Rounds {
+			if s.Round < s.Rules.MaxRounds() {
+				s.nextRound()
+				return
+			}
+			winner = s.roundsLeader()
+		}
+	}
+
+	s.Over = true
+	s.Winner = winner
+}
+
+// MaxRounds is how many rounds a match lasts at most: as many as it takes for
+// one of two teams to win Rounds of them when none is drawn.
+func (r Rules) MaxRounds() int {
+	return max(2*r.Rounds-1, 1)
+}
+
+// roundsLeader is the team that won the most rounds, or 0 when several tie.
+func (s *Simulation) roundsLeader() int {
+	leader, best, tied := 0, -1, false
+	for _, a := range s.Players {
+		switch won := s.RoundsWon(a.Team); {
+		case a.Team == leader:
+		case won > best:
+			leader, best, tied = a.Team, won, false
+		case won == best:
+			tied = true
+		}
+	}
+	if tied {
+		return 0
+	}
+	return leader
+}
+
+// RoundsWon is how many rounds team has won so far.
+func (s *Simulation) RoundsWon(team int) int {
+	for _, a := range s.Players {
+		if a.Team == team {
+			return a.RoundsWon
+		}
+	}
+	return 0
+}
+
+// startRound puts everyone back at the spawn points with full health and
+// stocks and holds them there for the countdown. The moving platforms start
+// over from their first waypoint; the rest of the level stays as it is.
+func (s *Simulation) startRound(round int) {
+	s.Round = round
+	s.World.Platforms = levels.BuildPlatforms(s.Level.Platforms)
+	s.Clock = 0
+	s.SuddenDeath = false
+	if s.Rules.Rounds > 1 {
+		s.Countdown = roundCountdown
+	}
+
+	clear(s.Projectiles)
+	s.Projectiles = s.Projectiles[:0]
+	for i, a := range s.Players {
+		if round > 1 {
+			spawn := levels.Spawn(s.Level.Spawns, i)
+			a.Respawn(spawn.X, spawn.Y)
+		}
+		a.Stocks = max(s.Rules.Stocks, 1)
+	}
+}
+
+func (s *Simulation) nextRound() {
+	s.startRound(s.Round + 1)
+}
+
+// startSuddenDeath knocks out every team but the leaders and leaves the
+// leaders one stock and one hit from losing it.
+func (s *Simulation) startSuddenDeath(leaders []int) {
+	s.SuddenDeath = true
+	for _, a := range s.Players {
+		if !slices.Contains(leaders, a.Team) {
+			a.Stocks = 0
+			a.Die()
+			continue
+		}
+		if a.Stocks > 0 {
+			a.Stocks = 1
+			a.Hp = min(a.Hp, suddenDeathHp)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package simulation_test
+
+import (
+	"testing"
+
+	"github.com/gassyrdaulet/go-fighting-game/simulation"
+	"github.com/gassyrdaulet/go-fighting-game/simulation/simtest"
+)
+
+func TestDrawnRoundsEndTheMatch(t *testing.T) {
+	sim := simtest.NewWithRules(t, simulation.Rules{Stocks: 1, Rounds: 2})
+
+	for round := 1; round <= sim.Rules.MaxRounds(); round++ {
+		if sim.Over {
+			t.Fatalf("match over after %d drawn rounds", round-1)
+		}
+		if sim.Round != round {
+			t.Fatalf("Round = %d, want %d", sim.Round, round)
+		}
+		sim.End(nil)
+	}
+
+	if !sim.Over || sim.Winner != 0 {
+		t.Errorf("Over = %v, Winner = %d after %d drawn rounds, want a drawn match", sim.Over, sim.Winner, sim.Rules.MaxRounds())
+	}
+}
+
+func TestLastRoundGoesToMostRoundsWon(t *testing.T) {
+	sim := simtest.NewWithRules(t, simulation.Rules{Stocks: 1, Rounds: 2})
+	team := sim.Players[1].Team
+
+	sim.End([]int{team})
+	sim.End(nil)
+	sim.End(nil)
+
+	if !sim.Over || sim.Winner != team {
+		t.Errorf("Over = %v, Winner = %d, want team %d to win", sim.Over, sim.Winner, team)
+	}
+}
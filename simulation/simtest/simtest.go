@@ -0,0 +1,92 @@
+// Package simtest sets up simulations for tests the way the game does, from
+// the level and character files in the repository.
+package simtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/characters"
+	"github.com/gassyrdaulet/go-fighting-game/levels"
+	"github.com/gassyrdaulet/go-fighting-game/simulation"
+)
+
+// Level is the level the simulations are loaded on.
+const Level = "ai-arena"
+
+// Rules are the rules the simulations are played by.
+var Rules = simulation.Rules{Stocks: 3}
+
+// Root makes the repository root the working directory for the rest of the
+// test, since the game reads its files relative to it.
+func Root(t testing.TB) {
+	t.Helper()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			t.Chdir(dir)
+			return
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatal("simtest: no go.mod above the working directory")
+		}
+		dir = parent
+	}
+}
+
+func Characters(t testing.TB) map[string]*characters.Character {
+	t.Helper()
+
+	Root(t)
+	chars, err := characters.LoadCharacters("characters/players.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chars
+}
+
+// New loads Level with two players playing by Rules.
+func New(t testing.TB) *simulation.Simulation {
+	t.Helper()
+	return NewWithRules(t, Rules)
+}
+
+// NewWithRules loads Level with two players playing by rules.
+func NewWithRules(t testing.TB, rules simulation.Rules) *simulation.Simulation {
+	t.Helper()
+
+	chars := Characters(t)
+	sim, err := simulation.New(Level, chars, levels.DefaultCharacterIDs(2, chars), nil, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sim
+}
+
+// Inputs are what two players press on tick: they run at each other, jump
+// now and then and trade attacks and blocks, all decided by the tick alone.
+func Inputs(tick int) []base.Input {
+	return []base.Input{
+		{
+			Right:  tick%240 < 150,
+			Left:   tick%240 >= 170,
+			Up:     tick%90 == 0,
+			Attack: tick%20 == 0,
+			Block:  tick%300 > 280,
+		},
+		{
+			Left:   tick%200 < 120,
+			Right:  tick%200 >= 140,
+			Up:     tick%130 == 5,
+			Down:   tick%400 > 390,
+			Attack: tick%25 == 3,
+		},
+	}
+}
@@ -0,0 +1,131 @@
+package simulation
+
+import (
+	"github.com/gassyrdaulet/go-fighting-game/base"
+	"github.com/gassyrdaulet/go-fighting-game/base/physics"
+	"github.com/gassyrdaulet/go-fighting-game/characters"
+	"github.com/gassyrdaulet/go-fighting-game/entities/actor"
+	"github.com/gassyrdaulet/go-fighting-game/entities/projectile"
+	"github.com/gassyrdaulet/go-fighting-game/levels"
+)
+
+// Simulation is a whole match with nothing to draw it: the level, its physics
+// world and the actors. It doesn't poll any device either, every tick gets the
+// players' inputs from the caller, so it runs the same in the game window, in
+// tests and on a machine without a display.
+type Simulation struct {
+	LevelName   string
+	Level       *levels.LevelData
+	TileMap     *base.TileMap
+	World       *physics.World
+	Players     []*actor.Actor
+	Projectiles []*projectile.Projectile
+	Rules       Rules
+	Tick        int
+	// Round is the round being played, from 1. Countdown holds the players
+	// still until it starts and Clock counts the ticks played in it since.
+	Round     int
+	Countdown int
+	Clock     int
+	// Over is set once the match is decided, won by the team Winner or
+	// drawn when Winner is 0. SuddenDeath is set once the time limit ran out
+	// on a tie.
+	Over        bool
+	Winner      int
+	SuddenDeath bool
+}
+
+func New(
+	levelName string,
+	chars map[string]*characters.Character,
+	charIDs []string,
+	teams []int,
+	rules Rules,
+) (*Simulation, error) {
+	level, err := levels.LoadLevel(levelName)
+	if err != nil {
+		return nil, err
+	}
+
+	tileMap, err := levels.BuildTileMapFromLines(level.TileMap)
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := levels.SpawnPlayers(charIDs, chars, level.Spawns, teams)
+	if err != nil {
+		return nil, err
+	}
+	s := &Simulation{
+		LevelName: levelName,
+		Level:     level,
+		TileMap:   tileMap,
+		World:     physics.NewWorld(tileMap),
+		Players:   players,
+		Rules:     rules,
+	}
+	s.startRound(1)
+
+	return s, nil
+}
+
+// collideBodies lets the players still standing collide with each other this
+// tick, when the rules say they do.
+func (s *Simulation) collideBodies() {
+	clear(s.World.Bodies)
+	s.World.Bodies = s.World.Bodies[:0]
+	if !s.Rules.BodyCollision {
+		return
+	}
+	for _, a := range s.Players {
+		if !a.Dying && !a.Dead {
+			s.World.Bodies = append(s.World.Bodies, a.Body)
+		}
+	}
+}
+
+// Step advances the match by one tick. inputs[i] drives Players[i]; players
+// without an input stand still.
+func (s *Simulation) Step(inputs []base.Input) {
+	countingDown := s.Countdown > 0
+	if countingDown {
+		inputs = nil
+		s.Countdown--
+	}
+
+	if !countingDown {
+		s.World.MovePlatforms()
+	}
+	s.collideBodies()
+
+	for i, a := range s.Players {
+		var input base.Input
+		if i < len(inputs) {
+			input = inputs[i]
+		}
+		a.Update(input, s.World, s.Players, s.Rules.FriendlyFire)
+		if a.Shot != nil {
+			s.Projectiles = append(s.Projectiles, projectile.New(a.Shot, i, a))
+		}
+	}
+
+	if s.Rules.BodyCollision {
+		s.World.PushBodies()
+	}
+
+	alive := s.Projectiles[:0]
+	for _, p := range s.Projectiles {
+		p.Update(s.World, s.Players, s.Rules.FriendlyFire)
+		if !p.Dead {
+			alive = append(alive, p)
+		}
+	}
+	clear(s.Projectiles[len(alive):])
+	s.Projectiles = alive
+
+	s.Tick++
+	if !countingDown {
+		s.Clock++
+	}
+	s.judge()
+}
@@ -0,0 +1,41 @@
+package simulation_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gassyrdaulet/go-fighting-game/simulation/simtest"
+)
+
+func TestStepScriptedMatch(t *testing.T) {
+	sim := simtest.New(t)
+	spawnX := make([]float64, len(sim.Players))
+	for i, a := range sim.Players {
+		spawnX[i] = a.X
+	}
+
+	const ticks = 5000
+	for tick := range ticks {
+		sim.Step(simtest.Inputs(tick))
+
+		for i, a := range sim.Players {
+			for _, v := range []float64{a.X, a.Y, a.VX, a.VY} {
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					t.Fatalf("tick %d: player %d went to %v", tick, i+1, v)
+				}
+			}
+			if a.Hp < 0 {
+				t.Fatalf("tick %d: player %d has %d hp", tick, i+1, a.Hp)
+			}
+		}
+	}
+
+	if sim.Tick != ticks {
+		t.Errorf("Tick = %d, want %d", sim.Tick, ticks)
+	}
+	for i, a := range sim.Players {
+		if a.X == spawnX[i] {
+			t.Errorf("player %d never left x %v in %d ticks", i+1, a.X, ticks)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package simulation
+
+import (
+	"github.com/gassyrdaulet/go-fighting-game/base/physics"
+	"github.com/gassyrdaulet/go-fighting-game/entities/actor"
+	"github.com/gassyrdaulet/go-fighting-game/entities/projectile"
+)
+
+// State is everything that changes while a match runs. The level and the
+// characters never do, so they aren't part of it.
+type State struct {
+	Tick        int
+	Round       int
+	Countdown   int
+	Clock       int
+	Over        bool
+	Winner      int
+	SuddenDeath bool
+	Players     []actor.State
+	Projectiles []projectile.State
+	Platforms   []physics.Platform
+}
+
+func (s *Simulation) Save() *State {
+	state := &State{
+		Tick:        s.Tick,
+		Round:       s.Round,
+		Countdown:   s.Countdown,
+		Clock:       s.Clock,
+		Over:        s.Over,
+		Winner:      s.Winner,
+		SuddenDeath: s.SuddenDeath,
+		Players:     make([]actor.State, len(s.Players)),
+		Projectiles: make([]projectile.State, len(s.Projectiles)),
+		Platforms:   make([]physics.Platform, len(s.World.Platforms)),
+	}
+	for i, a := range s.Players {
+		state.Players[i] = a.Save()
+	}
+	for i, p := range s.Projectiles {
+		state.Projectiles[i] = p.Save()
+	}
+	for i, p := range s.World.Platforms {
+		state.Platforms[i] = p.Save()
+	}
+	return state
+}
+
+func (s *Simulation) Restore(state *State) {
+	s.Tick = state.Tick
+	s.Round = state.Round
+	s.Countdown = state.Countdown
+	s.Clock = state.Clock
+	s.Over = state.Over
+	s.Winner = state.Winner
+	s.SuddenDeath = state.SuddenDeath
+	for i, a := range s.Players {
+		a.Restore(state.Players[i])
+	}
+	s.Projectiles = s.Projectiles[:0]
+	for _, p := range state.Projectiles {
+		s.Projectiles = append(s.Projectiles, p.Restore())
+	}
+	for i, p := range s.World.Platforms {
+		p.Restore(state.Platforms[i])
+	}
+}
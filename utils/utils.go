@@ -1,36 +1,5 @@
 package utils
 
-import (
-	"image/png"
-	"log"
-	"os"
-
-	"github.com/hajimehoshi/ebiten/v2"
-)
-
-func LoadImage(path string) (*ebiten.Image, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	img, err := png.Decode(f)
-	if err != nil {
-		return nil, err
-	}
-
-	return ebiten.NewImageFromImage(img), nil
-}
-
-func MustLoad(path string) *ebiten.Image {
-	img, err := LoadImage(path)
-	if err != nil {
-		log.Fatal(err)
-	}
-	return img
-}
-
 func Clamp(v, min, max float64) float64 {
 	if v < min {
 		return min